@@ -0,0 +1,153 @@
+package neater
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+)
+
+type (
+	activationFunction func(float64) float64
+
+	// activation is an activation function along with the name it's
+	// registered under
+	activation struct {
+		name string
+		f    activationFunction
+	}
+)
+
+const (
+	ActivateSigmoid          = "sigmoid"
+	ActivateSteepenedSigmoid = "steepened_sigmoid"
+	ActivateTanh             = "tanh"
+	ActivateReLU             = "relu"
+	ActivateLeakyReLU        = "leaky_relu"
+	ActivateGaussian         = "gaussian"
+	ActivateSin              = "sin"
+	ActivateAbs              = "abs"
+	ActivateStep             = "step"
+	ActivateSoftsign         = "softsign"
+	ActivateClamped          = "clamped"
+	ActivateIdentity         = "identity"
+
+	// ActivateUnit is the identity function, it's kept for compatibility
+	ActivateUnit = "unit"
+)
+
+var (
+	// activationsMu guards activations
+	activationsMu sync.RWMutex
+
+	// activations maps activation function names to activation functions
+	activations = map[string]activationFunction{
+		ActivateSigmoid:          sigmoid,
+		ActivateSteepenedSigmoid: steepenedSigmoid,
+		ActivateTanh:             math.Tanh,
+		ActivateReLU:             relu,
+		ActivateLeakyReLU:        leakyReLU,
+		ActivateGaussian:         gaussian,
+		ActivateSin:              math.Sin,
+		ActivateAbs:              math.Abs,
+		ActivateStep:             step,
+		ActivateSoftsign:         softsign,
+		ActivateClamped:          clamped,
+		ActivateIdentity:         unit,
+		ActivateUnit:             unit,
+	}
+)
+
+// RegisterActivation makes the activation function ´f´ available under
+// ´name´, so that it can be named by Configuration.ActivationFunction and by
+// serialized genomes. A name can only be registered once.
+func RegisterActivation(name string, f func(float64) float64) error {
+	if name == "" || f == nil {
+		return fmt.Errorf("activation function needs a name and a function")
+	}
+
+	activationsMu.Lock()
+	defer activationsMu.Unlock()
+
+	if _, ok := activations[name]; ok {
+		return fmt.Errorf("activation function %q already registered", name)
+	}
+
+	activations[name] = f
+
+	return nil
+}
+
+// Activations returns the names of the registered activation functions in
+// alphabetical order
+func Activations() []string {
+	activationsMu.RLock()
+	defer activationsMu.RUnlock()
+
+	names := make([]string, 0, len(activations))
+	for name := range activations {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// lookupActivation returns the activation function registered under ´name´
+func lookupActivation(name string) (activation, bool) {
+	activationsMu.RLock()
+	defer activationsMu.RUnlock()
+
+	f, ok := activations[name]
+
+	return activation{name: name, f: f}, ok
+}
+
+func unit(x float64) float64 {
+	return x
+}
+
+// sigmoid is the logistic function
+func sigmoid(x float64) float64 {
+	return 1 / (1 + math.Exp(-x))
+}
+
+// steepenedSigmoid is the sigmoid used in the NEAT paper, it's close to linear
+// in [-0.5, 0.5]
+func steepenedSigmoid(x float64) float64 {
+	return 1 / (1 + math.Exp(-4.9*x))
+}
+
+func relu(x float64) float64 {
+	return math.Max(0, x)
+}
+
+func leakyReLU(x float64) float64 {
+	if x > 0 {
+		return x
+	}
+
+	return 0.01 * x
+}
+
+func gaussian(x float64) float64 {
+	return math.Exp(-x * x)
+}
+
+func step(x float64) float64 {
+	if x > 0 {
+		return 1
+	}
+
+	return 0
+}
+
+func softsign(x float64) float64 {
+	return x / (1 + math.Abs(x))
+}
+
+// clamped limits ´x´ to [-1, 1]
+func clamped(x float64) float64 {
+	return math.Max(-1, math.Min(1, x))
+}
@@ -0,0 +1,68 @@
+package neater
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestActivations(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  float64
+		expect float64
+	}{
+		{name: ActivateSigmoid, input: 0, expect: 0.5},
+		{name: ActivateSigmoid, input: 2, expect: 1 / (1 + math.Exp(-2))},
+		{name: ActivateSteepenedSigmoid, input: 1, expect: 1 / (1 + math.Exp(-4.9))},
+		{name: ActivateTanh, input: 1, expect: math.Tanh(1)},
+		{name: ActivateReLU, input: -1, expect: 0},
+		{name: ActivateReLU, input: 2, expect: 2},
+		{name: ActivateLeakyReLU, input: -1, expect: -0.01},
+		{name: ActivateGaussian, input: 0, expect: 1},
+		{name: ActivateSin, input: math.Pi / 2, expect: 1},
+		{name: ActivateAbs, input: -3, expect: 3},
+		{name: ActivateStep, input: -0.1, expect: 0},
+		{name: ActivateStep, input: 0.1, expect: 1},
+		{name: ActivateSoftsign, input: 1, expect: 0.5},
+		{name: ActivateClamped, input: 2, expect: 1},
+		{name: ActivateClamped, input: -2, expect: -1},
+		{name: ActivateIdentity, input: -2, expect: -2},
+		{name: ActivateUnit, input: 3, expect: 3},
+	}
+
+	for _, test := range tests {
+		a, ok := lookupActivation(test.name)
+		require.True(t, ok, test.name)
+		require.InDelta(t, test.expect, a.f(test.input), 1e-12, test.name)
+	}
+}
+
+func TestSigmoidIncreases(t *testing.T) {
+	for _, name := range []string{ActivateSigmoid, ActivateSteepenedSigmoid} {
+		a, _ := lookupActivation(name)
+		require.Less(t, a.f(-1), a.f(0), name)
+		require.Less(t, a.f(0), a.f(1), name)
+	}
+}
+
+func TestRegisterActivation(t *testing.T) {
+	square := func(x float64) float64 { return x * x }
+
+	require.NoError(t, RegisterActivation("test_square", square))
+	require.Error(t, RegisterActivation("test_square", square))
+	require.Error(t, RegisterActivation(ActivateSigmoid, square))
+	require.Error(t, RegisterActivation("", square))
+	require.Contains(t, Activations(), "test_square")
+
+	c := xorConfiguration(1)
+	c.ActivationFunction = "test_square"
+	require.NoError(t, c.Validate())
+
+	n, err := NewNeat(c)
+	require.NoError(t, err)
+
+	o := n.species[0].population[0]
+	require.Equal(t, 9.0, o.acts[o.outputs[0]].f(3))
+}
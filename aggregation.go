@@ -0,0 +1,123 @@
+package neater
+
+import (
+	"math"
+	"sort"
+)
+
+type (
+	aggregationFunction func([]float64) float64
+
+	// aggregation is an aggregation function along with its name
+	aggregation struct {
+		name string
+		f    aggregationFunction
+	}
+)
+
+const (
+	AggregateSum     = "sum"
+	AggregateProduct = "product"
+	AggregateMax     = "max"
+	AggregateMin     = "min"
+	AggregateMaxAbs  = "maxabs"
+	AggregateMean    = "mean"
+	AggregateMedian  = "median"
+)
+
+// aggregations maps aggregation function names to aggregation functions. The
+// functions are only ever called with at least one value.
+var aggregations = map[string]aggregationFunction{
+	AggregateSum:     sum,
+	AggregateProduct: product,
+	AggregateMax:     maxValue,
+	AggregateMin:     minValue,
+	AggregateMaxAbs:  maxAbs,
+	AggregateMean:    mean,
+	AggregateMedian:  median,
+}
+
+// Aggregations returns the names of the aggregation functions in
+// alphabetical order
+func Aggregations() []string {
+	names := make([]string, 0, len(aggregations))
+	for name := range aggregations {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// lookupAggregation returns the aggregation function named ´name´
+func lookupAggregation(name string) (aggregation, bool) {
+	f, ok := aggregations[name]
+
+	return aggregation{name: name, f: f}, ok
+}
+
+func sum(xs []float64) float64 {
+	s := 0.0
+	for _, x := range xs {
+		s += x
+	}
+
+	return s
+}
+
+func product(xs []float64) float64 {
+	p := 1.0
+	for _, x := range xs {
+		p *= x
+	}
+
+	return p
+}
+
+func maxValue(xs []float64) float64 {
+	m := xs[0]
+	for _, x := range xs[1:] {
+		m = math.Max(m, x)
+	}
+
+	return m
+}
+
+func minValue(xs []float64) float64 {
+	m := xs[0]
+	for _, x := range xs[1:] {
+		m = math.Min(m, x)
+	}
+
+	return m
+}
+
+// maxAbs returns the value with the largest magnitude, sign included
+func maxAbs(xs []float64) float64 {
+	m := xs[0]
+	for _, x := range xs[1:] {
+		if math.Abs(x) > math.Abs(m) {
+			m = x
+		}
+	}
+
+	return m
+}
+
+func mean(xs []float64) float64 {
+	return sum(xs) / float64(len(xs))
+}
+
+// median returns the middle value, or the mean of the two middle values when
+// there's an even number of them. ´xs´ is sorted in place.
+func median(xs []float64) float64 {
+	sort.Float64s(xs)
+
+	n := len(xs)
+	if n%2 == 1 {
+		return xs[n/2]
+	}
+
+	return (xs[n/2-1] + xs[n/2]) / 2
+}
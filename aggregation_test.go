@@ -0,0 +1,36 @@
+package neater
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAggregations(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  []float64
+		expect float64
+	}{
+		{name: AggregateSum, input: []float64{1, -2, 4}, expect: 3},
+		{name: AggregateProduct, input: []float64{1, -2, 4}, expect: -8},
+		{name: AggregateMax, input: []float64{1, -2, 4}, expect: 4},
+		{name: AggregateMin, input: []float64{1, -2, 4}, expect: -2},
+		{name: AggregateMaxAbs, input: []float64{1, -5, 4}, expect: -5},
+		{name: AggregateMean, input: []float64{1, -2, 4}, expect: 1},
+		{name: AggregateMedian, input: []float64{4, 1, -2}, expect: 1},
+		{name: AggregateMedian, input: []float64{4, 1, -2, 3}, expect: 2},
+		{name: AggregateMedian, input: []float64{7}, expect: 7},
+	}
+
+	for _, test := range tests {
+		a, ok := lookupAggregation(test.name)
+		require.True(t, ok, test.name)
+		require.InDelta(t, test.expect, a.f(test.input), 1e-12, test.name)
+	}
+
+	require.Equal(t, []string{
+		AggregateMax, AggregateMaxAbs, AggregateMean, AggregateMedian,
+		AggregateMin, AggregateProduct, AggregateSum,
+	}, Aggregations())
+}
@@ -0,0 +1,429 @@
+package neater
+
+import (
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+)
+
+type (
+	// checkpointData is the serialized form of a Neat instance
+	checkpointData struct {
+		Version     int
+		Config      Configuration
+		Inputs      []uint64
+		Outputs     []uint64
+		Stats       Stats
+		History     []Stats
+		Registry    registryData
+		Species     []speciesData
+		Best        *organismData
+		Fittest     *organismData
+		BestSpecies uint64
+		Pruning     pruningData
+	}
+
+	// pruningData is the serialized form of the phased pruning state
+	pruningData struct {
+		Simplifying bool
+		Floor       float64
+		Lowest      float64
+		Lowered     int
+	}
+
+	// registryData is the serialized form of a registry
+	registryData struct {
+		Generation  int
+		Nodes       uint64
+		Innovations uint64
+		Organisms   uint64
+		Species     uint64
+		Seed        int64
+		Draws       uint64
+		Conns       []connData
+		Splits      []splitData
+	}
+
+	// connData is the serialized form of a connection innovation
+	connData struct {
+		Input      uint64
+		Output     uint64
+		Innovation uint64
+		Seen       int
+	}
+
+	// splitData is the serialized form of a split innovation
+	splitData struct {
+		Input  uint64
+		Output uint64
+		Node   uint64
+		Alpha  uint64
+		Beta   uint64
+		Seen   int
+	}
+
+	// speciesData is the serialized form of a species
+	speciesData struct {
+		ID             uint64
+		Generation     int
+		Best           float64
+		Improved       int
+		Offspring      int
+		Representative *organismData
+		Champion       *organismData
+		Population     []organismData
+	}
+
+	// organismData is the serialized form of an organism
+	organismData struct {
+		Genome   genomeData
+		Adjusted float64
+	}
+)
+
+const (
+	// checkpointVersion is the version of the checkpoint format
+	checkpointVersion = 1
+)
+
+var (
+	ErrInvalidCheckpoint = errors.New("invalid checkpoint")
+)
+
+// Checkpoint writes the complete state of the population to ´w´, a Neat
+// instance restored from it with Restore continues training exactly where this
+// instance left off. Reporters and a user supplied Selector aren't part of the
+// checkpoint. A Neat instance using a user supplied random source can't be
+// checkpointed as the state of the source is unknown.
+func (n *Neat) Checkpoint(w io.Writer) error {
+	d, err := n.encode()
+	if err != nil {
+		return err
+	}
+
+	return gob.NewEncoder(w).Encode(d)
+}
+
+// Restore reads a checkpoint written by Checkpoint. The functions in ´opts´
+// are applied to the restored configuration before it's used, they can be
+// used to set the fields that aren't part of the checkpoint, such as Selector,
+// or to change settings such as Workers.
+func Restore(r io.Reader, opts ...func(*Configuration)) (*Neat, error) {
+	d := new(checkpointData)
+	if err := gob.NewDecoder(r).Decode(d); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCheckpoint, err)
+	}
+
+	return decodeNeat(d, opts)
+}
+
+// checkpoint writes a checkpoint to CheckpointPath if one is due
+func (n *Neat) checkpoint() error {
+	if n.conf.CheckpointInterval <= 0 || n.stats.Iterations%n.conf.CheckpointInterval != 0 {
+		return nil
+	}
+
+	return n.CheckpointFile(n.conf.CheckpointPath)
+}
+
+// CheckpointFile writes a checkpoint to the file ´path´. The checkpoint is
+// written to a temporary file that replaces ´path´ once complete so that an
+// interrupted write doesn't destroy the previous checkpoint.
+func (n *Neat) CheckpointFile(path string) error {
+	if path == "" {
+		return errors.New("no checkpoint path configured")
+	}
+
+	f, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if err := n.Checkpoint(f); err != nil {
+		f.Close()
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), path)
+}
+
+func (n *Neat) encode() (*checkpointData, error) {
+	if n.reg.source == nil {
+		return nil, errors.New("can't checkpoint a user supplied random source")
+	}
+
+	d := &checkpointData{
+		Version: checkpointVersion,
+		Config:  *n.conf,
+		Inputs:  nodeIDs(n.inputs),
+		Outputs: nodeIDs(n.outputs),
+		Stats:   n.stats,
+		History: n.history,
+		Registry: registryData{
+			Generation:  n.reg.generation,
+			Nodes:       n.reg.nodes.current(),
+			Innovations: n.reg.innovs.current(),
+			Organisms:   n.reg.organisms.current(),
+			Species:     n.reg.species.current(),
+			Seed:        n.reg.source.seed,
+			Draws:       n.reg.source.draws,
+		},
+		Species: make([]speciesData, len(n.species)),
+		Pruning: pruningData{
+			Simplifying: n.pruning.simplifying,
+			Floor:       n.pruning.floor,
+			Lowest:      n.pruning.lowest,
+			Lowered:     n.pruning.lowered,
+		},
+	}
+
+	// Neither can be serialized
+	d.Config.Selector = nil
+	d.Config.Source = nil
+
+	for p, c := range n.reg.conns {
+		d.Registry.Conns = append(d.Registry.Conns, connData{
+			Input:      uint64(p.input),
+			Output:     uint64(p.output),
+			Innovation: uint64(c.innov),
+			Seen:       c.seen,
+		})
+	}
+
+	for p, s := range n.reg.splits {
+		d.Registry.Splits = append(d.Registry.Splits, splitData{
+			Input:  uint64(p.input),
+			Output: uint64(p.output),
+			Node:   uint64(s.node),
+			Alpha:  uint64(s.alpha),
+			Beta:   uint64(s.beta),
+			Seen:   s.seen,
+		})
+	}
+
+	var err error
+	for i, s := range n.species {
+		x := &d.Species[i]
+		x.ID = uint64(s.id)
+		x.Generation = s.generation
+		x.Best = s.best
+		x.Improved = s.improved
+		x.Offspring = s.offspring
+		x.Population = make([]organismData, len(s.population))
+
+		if x.Representative, err = encodeOrganism(s.rep); err != nil {
+			return nil, err
+		}
+
+		if x.Champion, err = encodeOrganism(s.champ); err != nil {
+			return nil, err
+		}
+
+		for j, o := range s.population {
+			y, err := encodeOrganism(o)
+			if err != nil {
+				return nil, err
+			}
+
+			x.Population[j] = *y
+		}
+	}
+
+	if d.Best, err = encodeOrganism(n.best); err != nil {
+		return nil, err
+	}
+
+	if d.Fittest, err = encodeOrganism(n.fittest); err != nil {
+		return nil, err
+	}
+
+	if n.bestSpecies != nil {
+		d.BestSpecies = uint64(n.bestSpecies.id)
+	}
+
+	return d, nil
+}
+
+// encodeOrganism returns the serialized form of ´o´, or nil if ´o´ is nil
+func encodeOrganism(o *organism) (*organismData, error) {
+	if o == nil {
+		return nil, nil
+	}
+
+	g, err := encodeGenome(o)
+	if err != nil {
+		return nil, err
+	}
+
+	return &organismData{Genome: *g, Adjusted: o.adjusted}, nil
+}
+
+func decodeNeat(d *checkpointData, opts []func(*Configuration)) (*Neat, error) {
+	if d.Version != checkpointVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidCheckpoint, d.Version)
+	}
+
+	conf := d.Config
+	for _, opt := range opts {
+		opt(&conf)
+	}
+
+	if conf.Source != nil {
+		return nil, fmt.Errorf("%w: can't restore into a user supplied random source", ErrInvalidCheckpoint)
+	}
+
+	if err := conf.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := conf.resolve(); err != nil {
+		return nil, err
+	}
+
+	reg := newRegistry(&conf)
+	reg.generation = d.Registry.Generation
+	reg.nodes.restore(d.Registry.Nodes)
+	reg.innovs.restore(d.Registry.Innovations)
+	reg.organisms.restore(d.Registry.Organisms)
+	reg.species.restore(d.Registry.Species)
+
+	reg.source = newCountingSource(d.Registry.Seed)
+	reg.source.skip(d.Registry.Draws)
+	reg.rand = rand.New(reg.source)
+
+	for _, c := range d.Registry.Conns {
+		reg.conns[nodePair{nodeID(c.Input), nodeID(c.Output)}] = &connInnovation{
+			innov: geneID(c.Innovation),
+			seen:  c.Seen,
+		}
+	}
+
+	for _, s := range d.Registry.Splits {
+		reg.splits[nodePair{nodeID(s.Input), nodeID(s.Output)}] = &splitInnovation{
+			node:  nodeID(s.Node),
+			alpha: geneID(s.Alpha),
+			beta:  geneID(s.Beta),
+			seen:  s.Seen,
+		}
+	}
+
+	n := &Neat{
+		conf:    &conf,
+		reg:     reg,
+		species: make([]*species, len(d.Species), max(len(d.Species), conf.MaxPopulationSize)),
+		inputs:  make([]nodeID, len(d.Inputs)),
+		outputs: make([]nodeID, len(d.Outputs)),
+		stats:   d.Stats,
+		history: d.History,
+		pruning: pruning{
+			simplifying: d.Pruning.Simplifying,
+			floor:       d.Pruning.Floor,
+			lowest:      d.Pruning.Lowest,
+			lowered:     d.Pruning.Lowered,
+		},
+	}
+
+	for i, id := range d.Inputs {
+		n.inputs[i] = nodeID(id)
+	}
+
+	for i, id := range d.Outputs {
+		n.outputs[i] = nodeID(id)
+	}
+
+	// organisms maps organism IDs to the restored organisms so that the
+	// representatives and champions refer to members of the population
+	organisms := make(map[organismID]*organism)
+
+	restore := func(x *organismData) (*organism, error) {
+		if x == nil {
+			return nil, nil
+		}
+
+		o, err := decodeGenome(&x.Genome)
+		if err != nil {
+			return nil, err
+		}
+
+		o.conf = n.conf
+		o.reg = n.reg
+		o.adjusted = x.Adjusted
+
+		return o, nil
+	}
+
+	decode := func(x *organismData) (*organism, error) {
+		if x == nil {
+			return nil, nil
+		}
+
+		if o, ok := organisms[organismID(x.Genome.ID)]; ok {
+			return o, nil
+		}
+
+		o, err := restore(x)
+		if err != nil {
+			return nil, err
+		}
+
+		organisms[o.id] = o
+
+		return o, nil
+	}
+
+	var err error
+	for i, x := range d.Species {
+		s := &species{
+			id:         speciesID(x.ID),
+			conf:       n.conf,
+			reg:        n.reg,
+			population: make([]*organism, len(x.Population)),
+			generation: x.Generation,
+			best:       x.Best,
+			improved:   x.Improved,
+			offspring:  x.Offspring,
+		}
+
+		for j := range x.Population {
+			if s.population[j], err = decode(&x.Population[j]); err != nil {
+				return nil, err
+			}
+		}
+
+		if s.rep, err = decode(x.Representative); err != nil {
+			return nil, err
+		}
+
+		if s.champ, err = decode(x.Champion); err != nil {
+			return nil, err
+		}
+
+		if s.id == speciesID(d.BestSpecies) {
+			n.bestSpecies = s
+		}
+
+		n.species[i] = s
+	}
+
+	if n.best, err = decode(d.Best); err != nil {
+		return nil, err
+	}
+
+	// The fittest organism is a copy that may differ from the population
+	// member sharing its ID
+	if n.fittest, err = restore(d.Fittest); err != nil {
+		return nil, err
+	}
+
+	return n, nil
+}
@@ -0,0 +1,139 @@
+package neater
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// withoutElapsed returns the statistics in ´h´ without the time spent, which
+// differs between runs
+func withoutElapsed(h []Stats) []Stats {
+	x := make([]Stats, len(h))
+	for i, s := range h {
+		s.Elapsed = 0
+		x[i] = s
+	}
+
+	return x
+}
+
+func TestCheckpoint(t *testing.T) {
+	tf, cf := xorFactories()
+
+	a, err := NewNeat(xorConfiguration(1))
+	require.NoError(t, err)
+
+	b, err := NewNeat(xorConfiguration(1))
+	require.NoError(t, err)
+
+	for i := 0; i < 10; i++ {
+		a.Train(tf, cf)
+		b.Train(tf, cf)
+	}
+
+	buf := new(bytes.Buffer)
+	require.NoError(t, b.Checkpoint(buf))
+
+	b, err = Restore(buf)
+	require.NoError(t, err)
+	require.Equal(t, withoutElapsed(a.History()), withoutElapsed(b.History()))
+
+	for i := 0; i < 10; i++ {
+		a.Train(tf, cf)
+		b.Train(tf, cf)
+	}
+
+	require.Equal(t, withoutElapsed(a.History()), withoutElapsed(b.History()))
+	require.Equal(t, a.Best().ID(), b.Best().ID())
+	require.Equal(t, a.Best().Genes(), b.Best().Genes())
+	require.Equal(t, newGenome(a.fittest).ID(), newGenome(b.fittest).ID())
+	require.Equal(t, newGenome(a.fittest).Fitness(), newGenome(b.fittest).Fitness())
+	require.Equal(t, len(a.species), len(b.species))
+	for i, s := range a.species {
+		require.Equal(t, s.id, b.species[i].id)
+		require.Equal(t, s.rep.id, b.species[i].rep.id)
+		require.Equal(t, len(s.population), len(b.species[i].population))
+	}
+}
+
+func TestCheckpointBeforeTraining(t *testing.T) {
+	n, err := NewNeat(xorConfiguration(1))
+	require.NoError(t, err)
+
+	buf := new(bytes.Buffer)
+	require.NoError(t, n.Checkpoint(buf))
+
+	r, err := Restore(buf)
+	require.NoError(t, err)
+	require.Nil(t, r.Best())
+	require.Equal(t, n.Stats(), r.Stats())
+}
+
+func TestCheckpointSource(t *testing.T) {
+	conf := xorConfiguration(1)
+	conf.Source = rand.NewSource(1)
+
+	n, err := NewNeat(conf)
+	require.NoError(t, err)
+	require.Error(t, n.Checkpoint(ioutil.Discard))
+}
+
+func TestRestoreInvalid(t *testing.T) {
+	_, err := Restore(bytes.NewReader([]byte("not a checkpoint")))
+	require.True(t, errors.Is(err, ErrInvalidCheckpoint))
+}
+
+func TestCheckpointInterval(t *testing.T) {
+	dir, err := ioutil.TempDir("", "neater")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	tf, cf := xorFactories()
+
+	conf := xorConfiguration(1)
+	conf.CheckpointInterval = 3
+	conf.CheckpointPath = filepath.Join(dir, "neat.ckpt")
+
+	n, err := NewNeat(conf)
+	require.NoError(t, err)
+
+	_, err = n.Run(context.Background(), tf, cf, MaxGenerations(7))
+	require.NoError(t, err)
+
+	f, err := os.Open(conf.CheckpointPath)
+	require.NoError(t, err)
+	defer f.Close()
+
+	r, err := Restore(f)
+	require.NoError(t, err)
+	require.Equal(t, 6, r.Stats().Iterations)
+
+	// Only the checkpoint itself is left behind
+	files, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+
+	// An explicit checkpoint replaces the file the same way
+	require.NoError(t, n.CheckpointFile(conf.CheckpointPath))
+	require.Error(t, n.CheckpointFile(""))
+
+	g, err := os.Open(conf.CheckpointPath)
+	require.NoError(t, err)
+	defer g.Close()
+
+	r, err = Restore(g)
+	require.NoError(t, err)
+	require.Equal(t, 7, r.Stats().Iterations)
+
+	files, err = ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+}
@@ -1,113 +1,118 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"io/ioutil"
 	"log"
-	"math/rand"
 	"neater"
 	"os"
 	"os/signal"
+	"runtime"
 )
 
 func main() {
+	config := flag.String("config", "", "read the configuration from a JSON, YAML or neat-python file")
+	resume := flag.String("resume", "", "resume training from a checkpoint")
+	flag.Parse()
 
-	rand.Seed(0)
 	tf := NewXORTrainerFactory()
 	cf := NewXORFitnessCalculatorFactory()
 
-	c := &neater.Configuration{
-		// Inputs is the number of inputs
-		Inputs: tf.Inputs(),
+	c := neater.DefaultConfiguration()
+	c.Workers = runtime.NumCPU()
+	c.CheckpointInterval = 10
 
-		// Outputs is the number of outputs
-		Outputs: tf.Outputs(),
+	if *config != "" {
+		var err error
+		c, err = neater.LoadConfiguration(*config)
 
-		// AddNodeMutationProb is the probability that a gene is disabled and a new Node is inserted
-		AddNodeMutationProb: 0.5,
-
-		// ConnectNodesMutationProb is the probability that a new gene connecting two nodes hkk
-		ConnectNodesMutationProb: 0.5,
-
-		// WeightMutationProb is the probability that a given gene's weight is mutated
-		WeightMutationProb: 0.8,
-
-		// WeightMutationPower is the threshold for wait mutations in one mutation
-		WeightMutationPower: 2.5,
-
-		// WeightMutationStandardDeviation
-		WeightMutationStandardDeviation: 0.5,
-
-		// PopulationThreshold is the maximum size of a species population
-		PopulationThreshold: 32,
-
-		// Recurrent controls whether recurrent connections are allowed
-		Recurrent: false,
-
-		// MaxPopulationSize is the maximum number of different species
-		MaxPopulationSize: 64,
+		var unknown *neater.UnknownKeysError
+		switch {
+		case errors.As(err, &unknown):
+			log.Print(err)
+		case err != nil:
+			log.Fatal(err)
+		}
+	}
 
-		// DisjointCoefficient
-		DisjointCoefficient: 2.0,
+	c.Inputs = tf.Inputs()
+	c.Outputs = tf.Outputs()
 
-		// ExcessCoefficient
-		ExcessCoefficient: 2.0,
+	if c.CheckpointPath == "" {
+		c.CheckpointPath = "xor.ckpt"
+	}
 
-		// WeightDifferenceCoefficient
-		WeightDifferenceCoefficient: 2.0,
+	n, err := newNeat(c, *resume)
+	if err != nil {
+		log.Fatal(err)
+	}
 
-		// CompatibilityThreshold controls how "distant" two genomes can be
-		// before they no longer belong to the same species
-		CompatibilityThreshold: 6.0,
+	n.AddReporter(neater.NewConsoleReporter(os.Stdout))
 
-		// CompatibilityModifier controls by how much the CompatibiltyThreshold
-		// is increased in every generation
-		CompatibilityModifier: 0.1,
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
 
-		// DropOffAge controls for how many generations a species is kept alive
-		// while not making progress
-		DropOffAge: 100,
+	sigc := make(chan os.Signal, 1)
+	signal.Notify(sigc, os.Interrupt)
+	go func() {
+		<-sigc
+		cancel()
+	}()
 
-		// SurvivalThreshold controls how many percent of the population top
-		// performers survive and reproduce, range (0, 1]
-		SurvivalThreshold: 1.0,
+	r, err := n.Run(ctx, tf, cf, neater.FitnessThreshold(0.99))
+	if err != nil && err != context.Canceled {
+		log.Fatal(err)
+	}
 
-		// MutationPower
-		MutationPower: 2.5,
+	log.Printf("%s after %d iterations, fitness %f", r.Reason, r.Iterations, r.Fitness)
 
-		// InitialPopulationSize
-		InitialPopulationSize: 8,
+	if err == context.Canceled {
+		if err := n.CheckpointFile(c.CheckpointPath); err != nil {
+			log.Fatal(err)
+		}
+	}
 
-		// ActivationFunction
-		ActivationFunction: neater.ActivateSigmoid,
+	if r.Best == nil {
+		return
+	}
 
-		NormalizeDistance: false,
+	f, err := os.Create("xor.dot")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
 
-		NormalizaDistanceThreshold: 20,
+	if err := neater.Graph(r.Best, f); err != nil {
+		log.Fatal(err)
 	}
 
-	n, err := neater.NewNeat(c)
+	b, err := json.MarshalIndent(r.Best, "", "  ")
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	sigc := make(chan os.Signal, 1)
-	signal.Notify(sigc, os.Interrupt)
+	if err := ioutil.WriteFile("xor.json", b, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
 
-	for {
-		select {
-		case <-sigc:
-			f, err := os.Create("xor.dot")
-			if err != nil {
-				panic(err)
-			}
-			defer f.Close()
-
-			if o := n.BestOrganism(); o != nil {
-				neater.Graph(o, f)
-				return
-			}
-		default:
-			n.Train(tf, cf)
-			//time.Sleep(time.Second)
-		}
+// newNeat creates a new Neat instance, or restores one from the checkpoint
+// ´resume´ if set
+func newNeat(c *neater.Configuration, resume string) (*neater.Neat, error) {
+	if resume == "" {
+		return neater.NewNeat(c)
 	}
+
+	f, err := os.Open(resume)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return neater.Restore(f, func(r *neater.Configuration) {
+		r.Workers = c.Workers
+	})
 }
@@ -41,6 +41,11 @@ func (t *XORTrainer) Reset() {
 	t.n = 0
 }
 
+// Batch returns the four XOR inputs so that they are evaluated in one call
+func (t *XORTrainer) Batch() [][]float64 {
+	return t.input
+}
+
 func NewXORFitnessCalculator() *XORFitnessCalculator {
 	return new(XORFitnessCalculator)
 }
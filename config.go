@@ -1,87 +1,525 @@
 package neater
 
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+)
+
 type (
 	ActivationFunction string
 
+	// FieldError describes a problem with a single setting of a
+	// Configuration
+	FieldError struct {
+		// Field is the name of the setting
+		Field string
+
+		// Msg describes the problem
+		Msg string
+	}
+
+	// ValidationError lists every problem found in a Configuration
+	ValidationError []*FieldError
+
 	Configuration struct {
 		// Inputs is the number of inputs
-		Inputs int
+		Inputs int `json:"inputs" yaml:"inputs"`
 
 		// Outputs is the number of outputs
-		Outputs int
+		Outputs int `json:"outputs" yaml:"outputs"`
 
-		// WeightMutationProb is the probability that a given gene's weight is mutated
-		WeightMutationProb float64
+		// WeightMutationProb is the probability that a given gene's weight is
+		// mutated, DefaultConfiguration sets it to 0.8
+		WeightMutationProb float64 `json:"weight_mutation_prob" yaml:"weight_mutation_prob"`
 
-		// WeightMutationPower is the threshold for weight mutations in one mutation
-		WeightMutationPower float64
+		// WeightMutationPower is the threshold for weight mutations in one
+		// mutation, DefaultConfiguration sets it to 2.5
+		WeightMutationPower float64 `json:"weight_mutation_power" yaml:"weight_mutation_power"`
 
-		// WeightMutationStandardDeviation
-		WeightMutationStandardDeviation float64
+		// WeightMutationStandardDeviation is the standard deviation of weight
+		// mutations, DefaultConfiguration sets it to 0.5
+		WeightMutationStandardDeviation float64 `json:"weight_mutation_standard_deviation" yaml:"weight_mutation_standard_deviation"`
 
-		// AddNodeMutationProb is the probability that a gene is disabled and a new Node is inserted
-		AddNodeMutationProb float64
+		// AddNodeMutationProb is the probability that a gene is disabled and a
+		// new Node is inserted, DefaultConfiguration sets it to 0.5
+		AddNodeMutationProb float64 `json:"add_node_mutation_prob" yaml:"add_node_mutation_prob"`
 
-		// ConnectNodesMutationProb is the probability that a new gene connecting two nodes hkk
-		ConnectNodesMutationProb float64
+		// ConnectNodesMutationProb is the probability that a new gene connecting
+		// two nodes is added, DefaultConfiguration sets it to 0.5
+		ConnectNodesMutationProb float64 `json:"connect_nodes_mutation_prob" yaml:"connect_nodes_mutation_prob"`
 
-		// PopulationThreshold is the maximum size of a species population
-		PopulationThreshold int
+		// DeleteConnectionMutationProb is the probability that a gene,
+		// enabled or not, is removed
+		DeleteConnectionMutationProb float64 `json:"delete_connection_mutation_prob" yaml:"delete_connection_mutation_prob"`
+
+		// DeleteNodeMutationProb is the probability that a hidden node is
+		// removed along with its bias and every gene connecting it
+		DeleteNodeMutationProb float64 `json:"delete_node_mutation_prob" yaml:"delete_node_mutation_prob"`
+
+		// PhasedPruning alternates between complexifying phases, in which
+		// nodes and connections are only ever added, and simplifying phases,
+		// in which they are only ever deleted. A simplifying phase starts
+		// when the mean complexity of the population exceeds the mean complexity
+		// at the end of the last simplifying phase by PruningThreshold, and
+		// ends once the mean complexity hasn't decreased for
+		// PruningStagnation generations. The complexity of a genome is its
+		// number of hidden nodes and genes, disabled genes included.
+		PhasedPruning bool `json:"phased_pruning" yaml:"phased_pruning"`
+
+		// PruningThreshold is the growth of the mean complexity that starts a
+		// simplifying phase
+		PruningThreshold float64 `json:"pruning_threshold" yaml:"pruning_threshold"`
+
+		// PruningStagnation is the number of generations without a decrease
+		// of the mean complexity that ends a simplifying phase
+		PruningStagnation int `json:"pruning_stagnation" yaml:"pruning_stagnation"`
+
+		// PopulationThreshold is the maximum size of a species population,
+		// DefaultConfiguration sets it to 32
+		PopulationThreshold int `json:"population_threshold" yaml:"population_threshold"`
 
 		// Recurrent controls whether recurrent connections are allowed
-		Recurrent bool
+		Recurrent bool `json:"recurrent" yaml:"recurrent"`
 
 		// RecurrentConnProb the probability that a new connection is recurrent
-		RecurrentConnProb float64
+		RecurrentConnProb float64 `json:"recurrent_conn_prob" yaml:"recurrent_conn_prob"`
+
+		// SettleIterations is the number of times every node of a recurrent
+		// network is updated for each input, defaults to 1. Only used when
+		// Recurrent is set, organisms are then evaluated on the inputs of
+		// the Trainer as a sequence.
+		SettleIterations int `json:"settle_iterations" yaml:"settle_iterations"`
 
-		// MaxPopulationSize is the maximum number of different species
-		MaxPopulationSize int
+		// MaxPopulationSize is the maximum number of different species,
+		// DefaultConfiguration sets it to 64
+		MaxPopulationSize int `json:"max_population_size" yaml:"max_population_size"`
 
-		// DisjointCoefficient
-		DisjointCoefficient float64
+		// PopulationSize is the total number of organisms across all species.
+		// The organisms are split across the species in proportion to their
+		// adjusted fitness. 0 keeps the population at InitialPopulationSize.
+		// DefaultConfiguration sets it to 150.
+		PopulationSize int `json:"population_size" yaml:"population_size"`
 
-		// ExcessCoefficient
-		ExcessCoefficient float64
+		// DisjointCoefficient weighs the disjoint genes in the distance between
+		// two genomes, DefaultConfiguration sets it to 2
+		DisjointCoefficient float64 `json:"disjoint_coefficient" yaml:"disjoint_coefficient"`
 
-		// WeightDifferenceCoefficient
-		WeightDifferenceCoefficient float64
+		// ExcessCoefficient weighs the excess genes in the distance between two
+		// genomes, DefaultConfiguration sets it to 2
+		ExcessCoefficient float64 `json:"excess_coefficient" yaml:"excess_coefficient"`
 
-		// CompatibilityThreshold
-		CompatibilityThreshold float64
+		// WeightDifferenceCoefficient weighs the weight difference of matching
+		// genes in the distance between two genomes, DefaultConfiguration
+		// sets it to 2
+		WeightDifferenceCoefficient float64 `json:"weight_difference_coefficient" yaml:"weight_difference_coefficient"`
 
-		// CompatibilityModifier
-		CompatibilityModifier float64
+		// CompatibilityThreshold controls how distant two genomes can be before
+		// they no longer belong to the same species, DefaultConfiguration
+		// sets it to 6
+		CompatibilityThreshold float64 `json:"compatibility_threshold" yaml:"compatibility_threshold"`
 
-		// DropOffAge
-		DropOffAge int
+		// CompatibilityModifier controls by how much the CompatibilityThreshold
+		// of a species is increased in every generation, DefaultConfiguration
+		// sets it to 0.1
+		CompatibilityModifier float64 `json:"compatibility_modifier" yaml:"compatibility_modifier"`
+
+		// DropOffAge controls for how many generations a species is kept alive
+		// while not improving its best fitness, 0 disables the culling.
+		// DefaultConfiguration sets it to 100.
+		DropOffAge int `json:"drop_off_age" yaml:"drop_off_age"`
 
 		// SurvivalThreshold controls how many percent of the population top
-		// performers survive and reproduce, range (0, 1]
-		SurvivalThreshold float64
+		// performers survive and reproduce, range (0, 1]. DefaultConfiguration
+		// sets it to 1.
+		SurvivalThreshold float64 `json:"survival_threshold" yaml:"survival_threshold"`
+
+		// Selection is the name of the strategy used to pick parents among
+		// the survivors of a species, defaults to SelectUniform
+		Selection string `json:"selection" yaml:"selection"`
+
+		// TournamentSize is the number of organisms competing in each
+		// tournament when Selection is SelectTournament, defaults to 2
+		TournamentSize int `json:"tournament_size" yaml:"tournament_size"`
+
+		// Selector is a user defined selection strategy, it takes precedence
+		// over Selection
+		Selector Selector `json:"-" yaml:"-"`
+
+		// MutationPower is set to 2.5 by DefaultConfiguration
+		MutationPower float64 `json:"mutation_power" yaml:"mutation_power"`
+
+		// InitialPopulationSize is the number of organisms in the initial
+		// population, DefaultConfiguration sets it to 8
+		InitialPopulationSize int `json:"initial_population_size" yaml:"initial_population_size"`
+
+		// InitialConnection is the name of the strategy connecting the nodes
+		// of the initial organisms, one of the Connect constants. Defaults to
+		// ConnectFull.
+		InitialConnection string `json:"initial_connection" yaml:"initial_connection"`
+
+		// InitialConnectionProb is the probability that an input node is
+		// connected to an output node when InitialConnection is
+		// ConnectRandom
+		InitialConnectionProb float64 `json:"initial_connection_prob" yaml:"initial_connection_prob"`
+
+		// InitialHiddenNodes is the size of the hidden layer placed between
+		// the input and output nodes of the initial organisms, the layer is
+		// fully connected on both sides. Requires ConnectFull.
+		InitialHiddenNodes int `json:"initial_hidden_nodes" yaml:"initial_hidden_nodes"`
+
+		// ActivationFunction is the name of the activation function given to
+		// new hidden and output nodes, either one of the Activate constants or
+		// a name registered with RegisterActivation. Defaults to
+		// ActivateSigmoid.
+		ActivationFunction string `json:"activation_function" yaml:"activation_function"`
+
+		// ActivationOptions are the names of the activation functions a node
+		// can be given by the activation mutation, defaults to
+		// ActivationFunction alone
+		ActivationOptions []string `json:"activation_options" yaml:"activation_options"`
+
+		// ActivationMutationProb is the probability that the activation
+		// function of a given hidden or output node is replaced by one of the
+		// ActivationOptions
+		ActivationMutationProb float64 `json:"activation_mutation_prob" yaml:"activation_mutation_prob"`
 
-		// MutationPower
-		MutationPower float64
+		// AggregationFunction is the name of the function combining the
+		// weighted inputs of new hidden and output nodes, one of the Aggregate
+		// constants. Defaults to AggregateSum.
+		AggregationFunction string `json:"aggregation_function" yaml:"aggregation_function"`
 
-		// InitialPopulationSize
-		InitialPopulationSize int
+		// AggregationOptions are the names of the aggregation functions a
+		// node can be given by the aggregation mutation, defaults to
+		// AggregationFunction alone
+		AggregationOptions []string `json:"aggregation_options" yaml:"aggregation_options"`
 
-		// ActivationFunction
-		ActivationFunction string
+		// AggregationMutationProb is the probability that the aggregation
+		// function of a given hidden or output node is replaced by one of the
+		// AggregationOptions
+		AggregationMutationProb float64 `json:"aggregation_mutation_prob" yaml:"aggregation_mutation_prob"`
 
-		// NormalizeDistance
-		NormalizeDistance bool
+		// NormalizeDistance controls whether the distance between two genomes
+		// is normalized by the size of the largest genome
+		NormalizeDistance bool `json:"normalize_distance" yaml:"normalize_distance"`
 
-		// NormalizaDistanceThreshold
-		NormalizaDistanceThreshold int
+		// NormalizaDistanceThreshold is the genome size from which the distance
+		// is normalized, DefaultConfiguration sets it to 20
+		NormalizaDistanceThreshold int `json:"normalize_distance_threshold" yaml:"normalize_distance_threshold"`
 
-		// InitialBiasWeight
-		InitialBiasWeight float64
+		// InitialBiasWeight is the weight of new bias connections when
+		// BiasInit is WeightInitConstant, and the mean of their weights when
+		// it's WeightInitNormal
+		InitialBiasWeight float64 `json:"initial_bias_weight" yaml:"initial_bias_weight"`
 
-		activate activationFunction
+		// BiasInitStdDev is the standard deviation of the weights of new bias
+		// connections when BiasInit is WeightInitNormal, 0 gives every bias
+		// connection InitialBiasWeight
+		BiasInitStdDev float64 `json:"bias_init_stddev" yaml:"bias_init_stddev"`
+
+		// BiasInit is the name of the distribution the weights of new bias
+		// connections are drawn from, one of the WeightInit constants. A bias
+		// connection is the only one of its kind entering a node, Xavier and
+		// He draw it with a fan-in of 1. Defaults to WeightInitNormal.
+		BiasInit string `json:"bias_init" yaml:"bias_init"`
+
+		// BiasInitMin and BiasInitMax bound the weights of new bias
+		// connections when BiasInit is WeightInitUniform
+		BiasInitMin float64 `json:"bias_init_min" yaml:"bias_init_min"`
+		BiasInitMax float64 `json:"bias_init_max" yaml:"bias_init_max"`
+
+		// WeightInit is the name of the distribution the weights of the
+		// initial connections and of the connections added by mutation are
+		// drawn from, one of the WeightInit constants. Bias connections are
+		// drawn as set by BiasInit. Defaults to WeightInitConstant.
+		WeightInit string `json:"weight_init" yaml:"weight_init"`
+
+		// WeightInitMin and WeightInitMax bound the weights drawn by
+		// WeightInitUniform
+		WeightInitMin float64 `json:"weight_init_min" yaml:"weight_init_min"`
+		WeightInitMax float64 `json:"weight_init_max" yaml:"weight_init_max"`
+
+		// WeightInitMean and WeightInitStdDev are the mean and the standard
+		// deviation of the weights drawn by WeightInitNormal
+		WeightInitMean   float64 `json:"weight_init_mean" yaml:"weight_init_mean"`
+		WeightInitStdDev float64 `json:"weight_init_stddev" yaml:"weight_init_stddev"`
+
+		// Workers is the number of organisms that are evaluated concurrently,
+		// defaults to 1. The TrainerFactory and FitnessCalculatorFactory must
+		// be safe for concurrent use when Workers is greater than 1.
+		Workers int `json:"workers" yaml:"workers"`
+
+		// Seed seeds the random source of the Neat instance, two runs with the
+		// same configuration and seed evolve identically
+		Seed int64 `json:"seed" yaml:"seed"`
+
+		// Source is a user supplied random source, it takes precedence over
+		// Seed. The source must not be shared between Neat instances. A Neat
+		// instance using a user supplied source can't be checkpointed.
+		Source rand.Source `json:"-" yaml:"-"`
+
+		// CheckpointInterval controls how often Run writes a checkpoint to
+		// CheckpointPath, every CheckpointInterval generations. 0 disables
+		// automatic checkpoints.
+		CheckpointInterval int `json:"checkpoint_interval" yaml:"checkpoint_interval"`
+
+		// CheckpointPath is the file automatic checkpoints are written to,
+		// every checkpoint replaces the previous one
+		CheckpointPath string `json:"checkpoint_path" yaml:"checkpoint_path"`
+
+		// InnovationHistory controls for how many generations an innovation
+		// is remembered after it was last seen, so that the same structural
+		// mutation is given the same innovation number. 0 means innovations
+		// are remembered for the whole run.
+		InnovationHistory int `json:"innovation_history" yaml:"innovation_history"`
+
+		activation         activation
+		activationOptions  []activation
+		aggregation        aggregation
+		aggregationOptions []aggregation
+		selector           Selector
+		connection         connectStrategy
+		weightInit         weightInit
+		biasInit           weightInit
 	}
 )
 
 const (
-	ActivateSigmoid = "sigmoid"
-	ActivateUnit    = "unit"
+	// ConnectFull connects every input node to every output node
+	ConnectFull = "full"
+	// ConnectFlow connects each input node to one output node, cycling
+	// through the nodes of the smaller set
+	ConnectFlow = "flow"
+	// ConnectNone leaves the nodes unconnected
+	ConnectNone = "none"
+	// ConnectRandom connects each input node to each output node with
+	// probability InitialConnectionProb
+	ConnectRandom = "random"
+	// ConnectFSNEAT connects a single random input node to a random output
+	// node, so that evolution selects the relevant inputs (FS-NEAT)
+	ConnectFSNEAT = "fs_neat"
 )
+
+// connectStrategies maps the initial connection names to strategies
+var connectStrategies = map[string]connectStrategy{
+	ConnectFull:   connectFull,
+	ConnectFlow:   connectFlow,
+	ConnectNone:   connectNone,
+	ConnectRandom: connectRandom,
+	ConnectFSNEAT: connectFSNEAT,
+	"":            connectFull,
+}
+
+// resolve looks up the activation function, the aggregation function, the
+// selection strategy, the initial connection and the weight initialization
+// named by the configuration and fills in defaults
+func (c *Configuration) resolve() error {
+	if c.ActivationFunction == "" {
+		c.ActivationFunction = ActivateSigmoid
+	}
+
+	a, ok := lookupActivation(c.ActivationFunction)
+	if !ok {
+		return fmt.Errorf("unknown activation function %q", c.ActivationFunction)
+	}
+	c.activation = a
+
+	c.activationOptions = []activation{a}
+	if len(c.ActivationOptions) > 0 {
+		c.activationOptions = make([]activation, len(c.ActivationOptions))
+		for i, name := range c.ActivationOptions {
+			if c.activationOptions[i], ok = lookupActivation(name); !ok {
+				return fmt.Errorf("unknown activation function %q", name)
+			}
+		}
+	}
+
+	if c.AggregationFunction == "" {
+		c.AggregationFunction = AggregateSum
+	}
+
+	g, ok := lookupAggregation(c.AggregationFunction)
+	if !ok {
+		return fmt.Errorf("unknown aggregation function %q", c.AggregationFunction)
+	}
+	c.aggregation = g
+
+	c.aggregationOptions = []aggregation{g}
+	if len(c.AggregationOptions) > 0 {
+		c.aggregationOptions = make([]aggregation, len(c.AggregationOptions))
+		for i, name := range c.AggregationOptions {
+			if c.aggregationOptions[i], ok = lookupAggregation(name); !ok {
+				return fmt.Errorf("unknown aggregation function %q", name)
+			}
+		}
+	}
+
+	switch {
+	case c.Selector != nil:
+		c.selector = c.Selector
+	case c.Selection == SelectRoulette:
+		c.selector = RouletteSelector{}
+	case c.Selection == SelectRank:
+		c.selector = RankSelector{}
+	case c.Selection == SelectTournament:
+		c.selector = TournamentSelector{Size: c.TournamentSize}
+	case c.Selection == SelectUniform, c.Selection == "":
+		c.selector = UniformSelector{}
+	default:
+		return fmt.Errorf("unknown selection strategy %q", c.Selection)
+	}
+
+	if c.connection, ok = connectStrategies[c.InitialConnection]; !ok {
+		return fmt.Errorf("unknown initial connection %q", c.InitialConnection)
+	}
+
+	if c.weightInit, ok = weightInits[c.WeightInit]; !ok {
+		return fmt.Errorf("unknown weight initialization %q", c.WeightInit)
+	}
+
+	c.biasInit = weightInitNormal
+	if c.BiasInit != "" {
+		if c.biasInit, ok = weightInits[c.BiasInit]; !ok {
+			return fmt.Errorf("unknown bias initialization %q", c.BiasInit)
+		}
+	}
+
+	if c.PopulationSize <= 0 {
+		c.PopulationSize = c.InitialPopulationSize
+	}
+
+	if c.SettleIterations <= 0 {
+		c.SettleIterations = 1
+	}
+
+	return nil
+}
+
+func (e *FieldError) Error() string {
+	return e.Field + ": " + e.Msg
+}
+
+func (e ValidationError) Error() string {
+	msgs := make([]string, len(e))
+	for i, f := range e {
+		msgs[i] = f.Error()
+	}
+
+	return "invalid configuration: " + strings.Join(msgs, "; ")
+}
+
+// Validate checks that every setting of the configuration is sensible. All
+// problems are reported at once in a ValidationError, nil is returned if
+// there are none.
+func (c *Configuration) Validate() error {
+	var errs ValidationError
+	check := func(ok bool, field, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, &FieldError{Field: field, Msg: fmt.Sprintf(format, args...)})
+		}
+	}
+
+	probability := func(field string, p float64) {
+		check(p >= 0 && p <= 1, field, "%v is not a probability in [0, 1]", p)
+	}
+
+	positive := func(field string, x int) {
+		check(x > 0, field, "must be greater than 0, got %d", x)
+	}
+
+	nonNegative := func(field string, x float64) {
+		check(x >= 0, field, "must not be negative, got %v", x)
+	}
+
+	positive("Inputs", c.Inputs)
+	positive("Outputs", c.Outputs)
+
+	probability("WeightMutationProb", c.WeightMutationProb)
+	probability("AddNodeMutationProb", c.AddNodeMutationProb)
+	probability("ConnectNodesMutationProb", c.ConnectNodesMutationProb)
+	probability("RecurrentConnProb", c.RecurrentConnProb)
+	check(c.Recurrent || c.RecurrentConnProb == 0, "RecurrentConnProb", "set without Recurrent")
+	probability("DeleteConnectionMutationProb", c.DeleteConnectionMutationProb)
+	probability("DeleteNodeMutationProb", c.DeleteNodeMutationProb)
+	nonNegative("SettleIterations", float64(c.SettleIterations))
+
+	nonNegative("WeightMutationPower", c.WeightMutationPower)
+	nonNegative("WeightMutationStandardDeviation", c.WeightMutationStandardDeviation)
+
+	positive("PopulationThreshold", c.PopulationThreshold)
+	positive("MaxPopulationSize", c.MaxPopulationSize)
+	positive("InitialPopulationSize", c.InitialPopulationSize)
+	nonNegative("PopulationSize", float64(c.PopulationSize))
+
+	nonNegative("DisjointCoefficient", c.DisjointCoefficient)
+	nonNegative("ExcessCoefficient", c.ExcessCoefficient)
+	nonNegative("WeightDifferenceCoefficient", c.WeightDifferenceCoefficient)
+	check(c.CompatibilityThreshold > 0, "CompatibilityThreshold", "must be greater than 0, got %v", c.CompatibilityThreshold)
+	nonNegative("NormalizaDistanceThreshold", float64(c.NormalizaDistanceThreshold))
+
+	nonNegative("DropOffAge", float64(c.DropOffAge))
+	check(c.SurvivalThreshold > 0 && c.SurvivalThreshold <= 1,
+		"SurvivalThreshold", "%v is outside of (0, 1]", c.SurvivalThreshold)
+
+	if c.Selector == nil {
+		switch c.Selection {
+		case SelectRoulette, SelectRank, SelectTournament, SelectUniform, "":
+		default:
+			check(false, "Selection", "unknown selection strategy %q", c.Selection)
+		}
+	}
+	nonNegative("TournamentSize", float64(c.TournamentSize))
+
+	s, ok := connectStrategies[c.InitialConnection]
+	check(ok, "InitialConnection", "unknown initial connection %q", c.InitialConnection)
+	probability("InitialConnectionProb", c.InitialConnectionProb)
+	nonNegative("InitialHiddenNodes", float64(c.InitialHiddenNodes))
+	check(c.InitialHiddenNodes <= 0 || s == connectFull, "InitialHiddenNodes", "requires the %s initial connection", ConnectFull)
+
+	_, ok = weightInits[c.WeightInit]
+	check(ok, "WeightInit", "unknown weight initialization %q", c.WeightInit)
+	check(c.WeightInitMin <= c.WeightInitMax, "WeightInitMax", "%v is less than WeightInitMin %v", c.WeightInitMax, c.WeightInitMin)
+	nonNegative("WeightInitStdDev", c.WeightInitStdDev)
+	nonNegative("BiasInitStdDev", c.BiasInitStdDev)
+	_, ok = weightInits[c.BiasInit]
+	check(ok, "BiasInit", "unknown bias initialization %q", c.BiasInit)
+	check(c.BiasInitMin <= c.BiasInitMax, "BiasInitMax", "%v is less than BiasInitMin %v", c.BiasInitMax, c.BiasInitMin)
+
+	if c.ActivationFunction != "" {
+		_, ok := lookupActivation(c.ActivationFunction)
+		check(ok, "ActivationFunction", "unknown activation function %q", c.ActivationFunction)
+	}
+	for _, name := range c.ActivationOptions {
+		_, ok := lookupActivation(name)
+		check(ok, "ActivationOptions", "unknown activation function %q", name)
+	}
+	probability("ActivationMutationProb", c.ActivationMutationProb)
+
+	if c.AggregationFunction != "" {
+		_, ok := lookupAggregation(c.AggregationFunction)
+		check(ok, "AggregationFunction", "unknown aggregation function %q", c.AggregationFunction)
+	}
+	for _, name := range c.AggregationOptions {
+		_, ok := lookupAggregation(name)
+		check(ok, "AggregationOptions", "unknown aggregation function %q", name)
+	}
+	probability("AggregationMutationProb", c.AggregationMutationProb)
+
+	if c.PhasedPruning {
+		check(c.PruningThreshold > 0, "PruningThreshold", "must be greater than 0, got %v", c.PruningThreshold)
+		positive("PruningStagnation", c.PruningStagnation)
+		check(c.DeleteConnectionMutationProb > 0 || c.DeleteNodeMutationProb > 0,
+			"PhasedPruning", "requires DeleteConnectionMutationProb or DeleteNodeMutationProb")
+	}
+
+	nonNegative("Workers", float64(c.Workers))
+	nonNegative("InnovationHistory", float64(c.InnovationHistory))
+	nonNegative("CheckpointInterval", float64(c.CheckpointInterval))
+	check(c.CheckpointInterval == 0 || c.CheckpointPath != "", "CheckpointPath", "required by CheckpointInterval")
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
@@ -0,0 +1,184 @@
+package neater
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(c *Configuration)
+		fields []string
+	}{
+		{
+			name:   "valid",
+			modify: func(c *Configuration) {},
+		},
+		{
+			name: "sizes",
+			modify: func(c *Configuration) {
+				c.Inputs = 0
+				c.PopulationThreshold = 0
+				c.InitialPopulationSize = -1
+				c.SettleIterations = -1
+			},
+			fields: []string{"Inputs", "SettleIterations", "PopulationThreshold", "InitialPopulationSize"},
+		},
+		{
+			name: "probabilities",
+			modify: func(c *Configuration) {
+				c.WeightMutationProb = 1.5
+				c.AddNodeMutationProb = -0.1
+			},
+			fields: []string{"WeightMutationProb", "AddNodeMutationProb"},
+		},
+		{
+			name: "survival threshold",
+			modify: func(c *Configuration) {
+				c.SurvivalThreshold = 0
+			},
+			fields: []string{"SurvivalThreshold"},
+		},
+		{
+			name: "recurrent connections without recurrence",
+			modify: func(c *Configuration) {
+				c.RecurrentConnProb = 0.2
+			},
+			fields: []string{"RecurrentConnProb"},
+		},
+		{
+			name: "recurrent connections",
+			modify: func(c *Configuration) {
+				c.Recurrent = true
+				c.RecurrentConnProb = 0.2
+			},
+		},
+		{
+			name: "unknown names",
+			modify: func(c *Configuration) {
+				c.Selection = "lottery"
+				c.ActivationFunction = "softplus"
+				c.ActivationOptions = []string{ActivateTanh, "swish"}
+				c.AggregationFunction = "mode"
+				c.AggregationOptions = []string{"range", AggregateMax}
+			},
+			fields: []string{
+				"Selection", "ActivationFunction", "ActivationOptions",
+				"AggregationFunction", "AggregationOptions",
+			},
+		},
+		{
+			name: "default names",
+			modify: func(c *Configuration) {
+				c.Selection = ""
+				c.ActivationFunction = ""
+				c.AggregationFunction = ""
+				c.InitialConnection = ""
+				c.WeightInit = ""
+			},
+		},
+		{
+			name: "custom selector",
+			modify: func(c *Configuration) {
+				c.Selection = "lottery"
+				c.Selector = UniformSelector{}
+			},
+		},
+		{
+			name: "initial connection",
+			modify: func(c *Configuration) {
+				c.InitialConnection = "sparse"
+				c.InitialConnectionProb = 2
+			},
+			fields: []string{"InitialConnection", "InitialConnectionProb"},
+		},
+		{
+			name: "hidden layer without full connection",
+			modify: func(c *Configuration) {
+				c.InitialConnection = ConnectFSNEAT
+				c.InitialHiddenNodes = 3
+			},
+			fields: []string{"InitialHiddenNodes"},
+		},
+		{
+			name: "weight initialization",
+			modify: func(c *Configuration) {
+				c.WeightInit = "glorot"
+				c.WeightInitMin = 1
+				c.WeightInitMax = -1
+				c.WeightInitStdDev = -1
+				c.BiasInit = "glorot"
+				c.BiasInitMin = 1
+				c.BiasInitMax = -1
+			},
+			fields: []string{"WeightInit", "WeightInitMax", "WeightInitStdDev", "BiasInit", "BiasInitMax"},
+		},
+		{
+			name: "delete mutations",
+			modify: func(c *Configuration) {
+				c.DeleteConnectionMutationProb = 1.5
+				c.DeleteNodeMutationProb = -0.5
+			},
+			fields: []string{"DeleteConnectionMutationProb", "DeleteNodeMutationProb"},
+		},
+		{
+			name: "phased pruning",
+			modify: func(c *Configuration) {
+				c.PhasedPruning = true
+			},
+			fields: []string{"PruningThreshold", "PruningStagnation", "PhasedPruning"},
+		},
+		{
+			name: "checkpoint without path",
+			modify: func(c *Configuration) {
+				c.CheckpointInterval = 10
+			},
+			fields: []string{"CheckpointPath"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := xorConfiguration(1)
+			test.modify(c)
+
+			err := c.Validate()
+			if test.fields == nil {
+				require.NoError(t, err)
+				return
+			}
+
+			var v ValidationError
+			require.True(t, errors.As(err, &v))
+
+			fields := make([]string, len(v))
+			for i, f := range v {
+				fields[i] = f.Field
+			}
+			require.Equal(t, test.fields, fields)
+		})
+	}
+}
+
+func TestNewNeatInvalid(t *testing.T) {
+	c := xorConfiguration(1)
+	c.ActivationFunction = "softplus"
+
+	_, err := NewNeat(c)
+
+	var v ValidationError
+	require.True(t, errors.As(err, &v))
+}
+
+func TestNewNeatDefaultActivation(t *testing.T) {
+	c := xorConfiguration(1)
+	c.ActivationFunction = ""
+
+	n, err := NewNeat(c)
+	require.NoError(t, err)
+	require.Equal(t, ActivateSigmoid, n.conf.ActivationFunction)
+	require.Equal(t, ActivateSigmoid, n.conf.activation.name)
+}
@@ -0,0 +1,599 @@
+package neater
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+)
+
+type (
+	// geneData is the serialized form of a gene
+	geneData struct {
+		Innovation uint64  `json:"innovation"`
+		Input      uint64  `json:"input"`
+		Output     uint64  `json:"output"`
+		Weight     float64 `json:"weight"`
+		Disabled   bool    `json:"disabled,omitempty"`
+	}
+
+	// nodeData is the serialized form of a hidden or output node
+	nodeData struct {
+		ID          uint64 `json:"id"`
+		Activation  string `json:"activation"`
+		Aggregation string `json:"aggregation"`
+	}
+
+	// genomeData is the serialized form of a genome
+	genomeData struct {
+		Version    int        `json:"version"`
+		ID         uint64     `json:"id"`
+		Fitness    float64    `json:"fitness"`
+		Inputs     []uint64   `json:"inputs"`
+		Outputs    []nodeData `json:"outputs"`
+		Hidden     []nodeData `json:"hidden"`
+		Genes      []geneData `json:"genes"`
+		Evaluation []int      `json:"evaluation"`
+		Bias       []geneData `json:"bias"`
+	}
+)
+
+const (
+	// genomeVersion is the version of the genome serialization format.
+	// Version 2 moved the activation function from the genome to the nodes,
+	// version 3 added the aggregation functions. Earlier versions are
+	// upgraded when decoded.
+	genomeVersion = 3
+
+	// genomeMagic identifies the binary genome format
+	genomeMagic = "NGNM"
+
+	// geneDisabled is the flag set on disabled genes in the binary format
+	geneDisabled = byte(1)
+)
+
+var (
+	ErrInvalidGenome = errors.New("invalid genome")
+)
+
+// MarshalJSON encodes the genome as JSON
+func (g *Genome) MarshalJSON() ([]byte, error) {
+	d, err := encodeGenome(g.o)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(d)
+}
+
+// UnmarshalJSON decodes a genome encoded by MarshalJSON
+func (g *Genome) UnmarshalJSON(b []byte) error {
+	// Check the version first as the layout differs between versions
+	v := struct {
+		Version int `json:"version"`
+	}{}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+
+	if v.Version < 1 || v.Version > genomeVersion {
+		return fmt.Errorf("%w: unsupported version %d", ErrInvalidGenome, v.Version)
+	}
+
+	d := new(genomeData)
+	if v.Version == 1 {
+		// Version 1 lists the IDs of the output and hidden nodes along with
+		// the activation function of the genome
+		x := struct {
+			*genomeData
+			Activation string   `json:"activation"`
+			Outputs    []uint64 `json:"outputs"`
+			Hidden     []uint64 `json:"hidden"`
+		}{genomeData: d}
+		if err := json.Unmarshal(b, &x); err != nil {
+			return err
+		}
+
+		d.Outputs = legacyNodes(x.Outputs, x.Activation)
+		d.Hidden = legacyNodes(x.Hidden, x.Activation)
+	} else if err := json.Unmarshal(b, d); err != nil {
+		return err
+	}
+
+	d.upgrade()
+
+	o, err := decodeGenome(d)
+	if err != nil {
+		return err
+	}
+
+	g.o = o
+
+	return nil
+}
+
+// MarshalBinary encodes the genome in a compact binary format
+func (g *Genome) MarshalBinary() ([]byte, error) {
+	d, err := encodeGenome(g.o)
+	if err != nil {
+		return nil, err
+	}
+
+	w := new(binaryWriter)
+	w.Write([]byte(genomeMagic))
+	w.uvarint(uint64(d.Version))
+	w.uvarint(d.ID)
+	w.float64(d.Fitness)
+	w.uvarints(d.Inputs)
+	w.nodes(d.Outputs)
+	w.nodes(d.Hidden)
+	w.genes(d.Genes)
+
+	w.uvarint(uint64(len(d.Evaluation)))
+	for _, i := range d.Evaluation {
+		w.uvarint(uint64(i))
+	}
+
+	w.genes(d.Bias)
+
+	return w.Bytes(), nil
+}
+
+// UnmarshalBinary decodes a genome encoded by MarshalBinary
+func (g *Genome) UnmarshalBinary(b []byte) error {
+	r := &binaryReader{r: bytes.NewReader(b)}
+
+	magic := make([]byte, len(genomeMagic))
+	if _, err := io.ReadFull(r.r, magic); err != nil || string(magic) != genomeMagic {
+		return fmt.Errorf("%w: not a binary genome", ErrInvalidGenome)
+	}
+
+	d := new(genomeData)
+	d.Version = int(r.uvarint())
+	if r.err == nil && (d.Version < 1 || d.Version > genomeVersion) {
+		return fmt.Errorf("%w: unsupported version %d", ErrInvalidGenome, d.Version)
+	}
+
+	d.ID = r.uvarint()
+	d.Fitness = r.float64()
+	if d.Version == 1 {
+		activation := r.string()
+		d.Inputs = r.uvarints()
+		d.Outputs = legacyNodes(r.uvarints(), activation)
+		d.Hidden = legacyNodes(r.uvarints(), activation)
+	} else {
+		d.Inputs = r.uvarints()
+		d.Outputs = r.nodes(d.Version)
+		d.Hidden = r.nodes(d.Version)
+	}
+	d.Genes = r.genes()
+
+	n := r.count()
+	d.Evaluation = make([]int, n)
+	for i := range d.Evaluation {
+		d.Evaluation[i] = int(r.uvarint())
+	}
+
+	d.Bias = r.genes()
+
+	if r.err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidGenome, r.err)
+	}
+
+	d.upgrade()
+
+	o, err := decodeGenome(d)
+	if err != nil {
+		return err
+	}
+
+	g.o = o
+
+	return nil
+}
+
+// legacyNodes returns the nodes ´ids´ of a version 1 genome, all of which
+// have the activation function of the genome
+func legacyNodes(ids []uint64, activation string) []nodeData {
+	ns := make([]nodeData, len(ids))
+	for i, id := range ids {
+		ns[i] = nodeData{ID: id, Activation: activation}
+	}
+
+	return ns
+}
+
+// upgrade brings genome data of an earlier version up to date. Nodes
+// predating version 3 sum their inputs.
+func (d *genomeData) upgrade() {
+	if d.Version >= 3 {
+		return
+	}
+
+	for _, ns := range [][]nodeData{d.Outputs, d.Hidden} {
+		for i := range ns {
+			ns[i].Aggregation = AggregateSum
+		}
+	}
+
+	d.Version = genomeVersion
+}
+
+func encodeGenome(o *organism) (*genomeData, error) {
+	d := &genomeData{
+		Version:    genomeVersion,
+		ID:         uint64(o.id),
+		Fitness:    o.fitness,
+		Inputs:     nodeIDs(o.inputs),
+		Outputs:    make([]nodeData, 0, len(o.outputs)),
+		Hidden:     make([]nodeData, 0, len(o.acts)),
+		Genes:      make([]geneData, len(o.oinnov)),
+		Evaluation: make([]int, len(o.oeval)),
+		Bias:       make([]geneData, len(o.obias)),
+	}
+
+	encodeNode := func(id nodeID) (nodeData, error) {
+		a := o.acts[id]
+		if _, ok := lookupActivation(a.name); !ok {
+			return nodeData{}, fmt.Errorf("unknown activation function %q", a.name)
+		}
+
+		g := o.aggs[id]
+		if _, ok := lookupAggregation(g.name); !ok {
+			return nodeData{}, fmt.Errorf("unknown aggregation function %q", g.name)
+		}
+
+		return nodeData{ID: uint64(id), Activation: a.name, Aggregation: g.name}, nil
+	}
+
+	for _, id := range o.outputs {
+		x, err := encodeNode(id)
+		if err != nil {
+			return nil, err
+		}
+
+		d.Outputs = append(d.Outputs, x)
+	}
+
+	for id := range o.nodes {
+		if o.terminalNodes[id] {
+			continue
+		}
+
+		x, err := encodeNode(id)
+		if err != nil {
+			return nil, err
+		}
+
+		d.Hidden = append(d.Hidden, x)
+	}
+
+	sort.Slice(d.Hidden, func(i, j int) bool {
+		return d.Hidden[i].ID < d.Hidden[j].ID
+	})
+
+	index := make(map[*gene]int, len(o.oinnov))
+	for i, g := range o.oinnov {
+		d.Genes[i] = encodeGene(g)
+		index[g] = i
+	}
+
+	for i, g := range o.oeval {
+		d.Evaluation[i] = index[g]
+	}
+
+	for i, g := range o.obias {
+		d.Bias[i] = encodeGene(g)
+	}
+
+	return d, nil
+}
+
+func encodeGene(g *gene) geneData {
+	return geneData{
+		Innovation: uint64(g.innov),
+		Input:      uint64(g.p.input),
+		Output:     uint64(g.p.output),
+		Weight:     g.weight,
+		Disabled:   g.disabled,
+	}
+}
+
+func decodeGenome(d *genomeData) (*organism, error) {
+	if d.Version != genomeVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidGenome, d.Version)
+	}
+
+	if len(d.Inputs) == 0 || len(d.Outputs) == 0 {
+		return nil, fmt.Errorf("%w: missing inputs or outputs", ErrInvalidGenome)
+	}
+
+	conf := &Configuration{
+		Inputs:  len(d.Inputs),
+		Outputs: len(d.Outputs),
+	}
+
+	o := &organism{
+		id:            organismID(d.ID),
+		conf:          conf,
+		inputs:        make([]nodeID, len(d.Inputs)),
+		outputs:       make([]nodeID, len(d.Outputs)),
+		oinnov:        make([]*gene, len(d.Genes)),
+		oeval:         make([]*gene, len(d.Evaluation)),
+		obias:         make([]*gene, len(d.Bias)),
+		nodes:         make(map[nodeID]float64),
+		acts:          make(map[nodeID]activation),
+		aggs:          make(map[nodeID]aggregation),
+		terminalNodes: make(map[nodeID]bool),
+		fitness:       d.Fitness,
+	}
+
+	addNode := func(id uint64) error {
+		if nodeID(id) == biasID {
+			return fmt.Errorf("%w: node %d is reserved for the bias", ErrInvalidGenome, id)
+		}
+
+		if _, ok := o.nodes[nodeID(id)]; ok {
+			return fmt.Errorf("%w: duplicate node %d", ErrInvalidGenome, id)
+		}
+
+		o.nodes[nodeID(id)] = 0
+
+		return nil
+	}
+
+	setFunctions := func(x nodeData) error {
+		a, ok := lookupActivation(x.Activation)
+		if !ok {
+			return fmt.Errorf("%w: node %d has unknown activation function %q", ErrInvalidGenome, x.ID, x.Activation)
+		}
+
+		g, ok := lookupAggregation(x.Aggregation)
+		if !ok {
+			return fmt.Errorf("%w: node %d has unknown aggregation function %q", ErrInvalidGenome, x.ID, x.Aggregation)
+		}
+
+		o.acts[nodeID(x.ID)] = a
+		o.aggs[nodeID(x.ID)] = g
+
+		return nil
+	}
+
+	for i, id := range d.Inputs {
+		if err := addNode(id); err != nil {
+			return nil, err
+		}
+		o.inputs[i] = nodeID(id)
+		o.terminalNodes[nodeID(id)] = true
+	}
+
+	for i, x := range d.Outputs {
+		if err := addNode(x.ID); err != nil {
+			return nil, err
+		}
+		if err := setFunctions(x); err != nil {
+			return nil, err
+		}
+		o.outputs[i] = nodeID(x.ID)
+		o.terminalNodes[nodeID(x.ID)] = true
+	}
+
+	for _, x := range d.Hidden {
+		if err := addNode(x.ID); err != nil {
+			return nil, err
+		}
+		if err := setFunctions(x); err != nil {
+			return nil, err
+		}
+	}
+
+	for i, x := range d.Genes {
+		g, err := decodeGene(x)
+		if err != nil {
+			return nil, err
+		}
+
+		if !o.hasNode(g.p.input) || !o.hasNode(g.p.output) {
+			return nil, fmt.Errorf("%w: gene %d refers to an unknown node", ErrInvalidGenome, x.Innovation)
+		}
+
+		o.oinnov[i] = g
+	}
+
+	if len(d.Evaluation) != len(d.Genes) {
+		return nil, fmt.Errorf("%w: evaluation order doesn't cover every gene", ErrInvalidGenome)
+	}
+
+	seen := make([]bool, len(d.Genes))
+	for i, j := range d.Evaluation {
+		if j < 0 || j >= len(d.Genes) || seen[j] {
+			return nil, fmt.Errorf("%w: invalid evaluation order", ErrInvalidGenome)
+		}
+
+		seen[j] = true
+		o.oeval[i] = o.oinnov[j]
+	}
+
+	for i, x := range d.Bias {
+		g, err := decodeGene(x)
+		if err != nil {
+			return nil, err
+		}
+
+		if g.p.input != biasID || !o.hasNode(g.p.output) || o.terminalNodes[g.p.output] {
+			return nil, fmt.Errorf("%w: invalid bias gene %d", ErrInvalidGenome, x.Innovation)
+		}
+
+		o.obias[i] = g
+	}
+
+	return o, nil
+}
+
+func decodeGene(x geneData) (*gene, error) {
+	if math.IsNaN(x.Weight) || math.IsInf(x.Weight, 0) {
+		return nil, fmt.Errorf("%w: gene %d has an invalid weight", ErrInvalidGenome, x.Innovation)
+	}
+
+	g := newGene(geneID(x.Innovation), nodePair{nodeID(x.Input), nodeID(x.Output)}, x.Weight)
+	g.disabled = x.Disabled
+
+	return g, nil
+}
+
+// binaryWriter writes the primitives of the binary genome format
+type binaryWriter struct {
+	bytes.Buffer
+}
+
+func (w *binaryWriter) uvarint(x uint64) {
+	var b [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(b[:], x)
+	w.Write(b[:n])
+}
+
+func (w *binaryWriter) float64(x float64) {
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], math.Float64bits(x))
+	w.Write(b[:])
+}
+
+func (w *binaryWriter) string(s string) {
+	w.uvarint(uint64(len(s)))
+	w.WriteString(s)
+}
+
+func (w *binaryWriter) uvarints(xs []uint64) {
+	w.uvarint(uint64(len(xs)))
+	for _, x := range xs {
+		w.uvarint(x)
+	}
+}
+
+func (w *binaryWriter) nodes(ns []nodeData) {
+	w.uvarint(uint64(len(ns)))
+	for _, n := range ns {
+		w.uvarint(n.ID)
+		w.string(n.Activation)
+		w.string(n.Aggregation)
+	}
+}
+
+func (w *binaryWriter) genes(gs []geneData) {
+	w.uvarint(uint64(len(gs)))
+	for _, g := range gs {
+		w.uvarint(g.Innovation)
+		w.uvarint(g.Input)
+		w.uvarint(g.Output)
+		w.float64(g.Weight)
+
+		var flags byte
+		if g.Disabled {
+			flags |= geneDisabled
+		}
+		w.WriteByte(flags)
+	}
+}
+
+// binaryReader reads the primitives of the binary genome format, the first
+// error encountered is kept and all subsequent reads are no-ops
+type binaryReader struct {
+	r   *bytes.Reader
+	err error
+}
+
+func (r *binaryReader) uvarint() uint64 {
+	if r.err != nil {
+		return 0
+	}
+
+	x, err := binary.ReadUvarint(r.r)
+	r.err = err
+
+	return x
+}
+
+// count reads a length prefix and makes sure that it's plausible given the
+// number of bytes remaining
+func (r *binaryReader) count() int {
+	n := r.uvarint()
+	if r.err == nil && n > uint64(r.r.Len()) {
+		r.err = io.ErrUnexpectedEOF
+	}
+
+	if r.err != nil {
+		return 0
+	}
+
+	return int(n)
+}
+
+func (r *binaryReader) float64() float64 {
+	if r.err != nil {
+		return 0
+	}
+
+	var b [8]byte
+	_, r.err = io.ReadFull(r.r, b[:])
+
+	return math.Float64frombits(binary.LittleEndian.Uint64(b[:]))
+}
+
+func (r *binaryReader) string() string {
+	b := make([]byte, r.count())
+	if r.err != nil {
+		return ""
+	}
+
+	_, r.err = io.ReadFull(r.r, b)
+
+	return string(b)
+}
+
+func (r *binaryReader) uvarints() []uint64 {
+	xs := make([]uint64, r.count())
+	for i := range xs {
+		xs[i] = r.uvarint()
+	}
+
+	return xs
+}
+
+// nodes reads nodes in the layout of the given version of the format,
+// version 2 nodes have no aggregation function
+func (r *binaryReader) nodes(version int) []nodeData {
+	ns := make([]nodeData, r.count())
+	for i := range ns {
+		ns[i].ID = r.uvarint()
+		ns[i].Activation = r.string()
+		if version >= 3 {
+			ns[i].Aggregation = r.string()
+		}
+	}
+
+	return ns
+}
+
+func (r *binaryReader) genes() []geneData {
+	gs := make([]geneData, r.count())
+	for i := range gs {
+		gs[i].Innovation = r.uvarint()
+		gs[i].Input = r.uvarint()
+		gs[i].Output = r.uvarint()
+		gs[i].Weight = r.float64()
+
+		if r.err != nil {
+			break
+		}
+
+		var flags byte
+		flags, r.err = r.r.ReadByte()
+		gs[i].Disabled = flags&geneDisabled != 0
+	}
+
+	return gs
+}
@@ -0,0 +1,276 @@
+package neater
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func evolvedGenome(t testing.TB) *Genome {
+	tf, cf := xorFactories()
+
+	// Mix activation and aggregation functions so that they are part of the
+	// round trip
+	conf := xorConfiguration(1)
+	conf.ActivationOptions = []string{ActivateSigmoid, ActivateTanh, ActivateGaussian, ActivateSin}
+	conf.ActivationMutationProb = 0.2
+	conf.AggregationOptions = []string{AggregateSum, AggregateProduct, AggregateMax, AggregateMedian}
+	conf.AggregationMutationProb = 0.2
+
+	n, err := NewNeat(conf)
+	require.NoError(t, err)
+
+	for i := 0; i < 10; i++ {
+		n.Train(tf, cf)
+	}
+
+	return n.Best()
+}
+
+func requireSameGenome(t *testing.T, a, b *Genome) {
+	require.Equal(t, a.ID(), b.ID())
+	require.Equal(t, a.Fitness(), b.Fitness())
+	require.Equal(t, a.Inputs(), b.Inputs())
+	require.Equal(t, a.Outputs(), b.Outputs())
+	require.Equal(t, a.Nodes(), b.Nodes())
+	require.Equal(t, a.Genes(), b.Genes())
+	require.Equal(t, a.BiasGenes(), b.BiasGenes())
+	require.Equal(t, len(a.o.oeval), len(b.o.oeval))
+	for i, g := range a.o.oeval {
+		require.Equal(t, g.innov, b.o.oeval[i].innov)
+	}
+
+	x, y := a.Network(), b.Network()
+	for _, input := range [][]float64{{0, 0}, {0, 1}, {1, 0}, {1, 1}} {
+		require.Equal(t, x.Eval(input), y.Eval(input))
+	}
+}
+
+func TestGenomeJSON(t *testing.T) {
+	a := evolvedGenome(t)
+
+	b, err := json.Marshal(a)
+	require.NoError(t, err)
+
+	c := new(Genome)
+	require.NoError(t, json.Unmarshal(b, c))
+
+	requireSameGenome(t, a, c)
+}
+
+func TestGenomeBinary(t *testing.T) {
+	a := evolvedGenome(t)
+
+	b, err := a.MarshalBinary()
+	require.NoError(t, err)
+
+	c := new(Genome)
+	require.NoError(t, c.UnmarshalBinary(b))
+
+	requireSameGenome(t, a, c)
+
+	// Every truncation of the encoding must be rejected
+	for i := 0; i < len(b); i++ {
+		err := new(Genome).UnmarshalBinary(b[:i])
+		require.True(t, errors.Is(err, ErrInvalidGenome), "length %d: %v", i, err)
+	}
+}
+
+func TestGenomeInvalidJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{
+			name: "unsupported version",
+			data: `{"version": 99, "inputs": [1], "outputs": [{"id": 2, "activation": "sigmoid", "aggregation": "sum"}]}`,
+		},
+		{
+			name: "unknown activation",
+			data: `{"version": 3, "inputs": [1], "outputs": [{"id": 2, "activation": "nope", "aggregation": "sum"}]}`,
+		},
+		{
+			name: "unknown aggregation",
+			data: `{"version": 3, "inputs": [1], "outputs": [{"id": 2, "activation": "sigmoid", "aggregation": "nope"}]}`,
+		},
+		{
+			name: "version 0",
+			data: `{"version": 0, "inputs": [1], "outputs": [{"id": 2, "activation": "sigmoid", "aggregation": "sum"}]}`,
+		},
+		{
+			name: "version 2 unknown activation",
+			data: `{"version": 2, "inputs": [1], "outputs": [{"id": 2, "activation": "nope"}]}`,
+		},
+		{
+			name: "version 1 unknown activation",
+			data: `{"version": 1, "activation": "nope", "inputs": [1], "outputs": [2]}`,
+		},
+		{
+			name: "unknown node",
+			data: `{"version": 3, "inputs": [1], "outputs": [{"id": 2, "activation": "sigmoid", "aggregation": "sum"}],
+				"genes": [{"innovation": 1, "input": 1, "output": 3, "weight": 1}], "evaluation": [0]}`,
+		},
+		{
+			name: "incomplete evaluation order",
+			data: `{"version": 3, "inputs": [1], "outputs": [{"id": 2, "activation": "sigmoid", "aggregation": "sum"}],
+				"genes": [{"innovation": 1, "input": 1, "output": 2, "weight": 1}], "evaluation": []}`,
+		},
+		{
+			name: "bias to terminal node",
+			data: `{"version": 3, "inputs": [1], "outputs": [{"id": 2, "activation": "sigmoid", "aggregation": "sum"}],
+				"bias": [{"innovation": 1, "input": 0, "output": 2, "weight": 1}]}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := json.Unmarshal([]byte(test.data), new(Genome))
+			require.True(t, errors.Is(err, ErrInvalidGenome), "%v", err)
+		})
+	}
+}
+
+func TestGenomeDeletions(t *testing.T) {
+	tf, cf := xorFactories()
+
+	// Deletions leave inputs without any gene
+	conf := xorConfiguration(1)
+	conf.DeleteConnectionMutationProb = 0.9
+	conf.DeleteNodeMutationProb = 0.2
+
+	n, err := NewNeat(conf)
+	require.NoError(t, err)
+
+	for i := 0; i < 60; i++ {
+		n.Train(tf, cf)
+	}
+
+	a := n.Best()
+	b, err := json.Marshal(a)
+	require.NoError(t, err)
+
+	c := new(Genome)
+	require.NoError(t, json.Unmarshal(b, c))
+	requireSameGenome(t, a, c)
+
+	require.NoError(t, n.Checkpoint(io.Discard))
+}
+
+func TestGenomeLegacyVersions(t *testing.T) {
+	tf, cf := xorFactories()
+
+	// Earlier versions only know the sigmoid activation of the genome and
+	// summed inputs
+	n, err := NewNeat(xorConfiguration(1))
+	require.NoError(t, err)
+
+	for i := 0; i < 10; i++ {
+		n.Train(tf, cf)
+	}
+
+	a := n.Best()
+	d, err := encodeGenome(a.o)
+	require.NoError(t, err)
+
+	ids := func(ns []nodeData) []uint64 {
+		xs := make([]uint64, len(ns))
+		for i, x := range ns {
+			xs[i] = x.ID
+		}
+
+		return xs
+	}
+
+	binaryGenome := func(version int) []byte {
+		w := new(binaryWriter)
+		w.Write([]byte(genomeMagic))
+		w.uvarint(uint64(version))
+		w.uvarint(d.ID)
+		w.float64(d.Fitness)
+
+		if version == 1 {
+			w.string(ActivateSigmoid)
+			w.uvarints(d.Inputs)
+			w.uvarints(ids(d.Outputs))
+			w.uvarints(ids(d.Hidden))
+		} else {
+			w.uvarints(d.Inputs)
+			for _, ns := range [][]nodeData{d.Outputs, d.Hidden} {
+				w.uvarint(uint64(len(ns)))
+				for _, x := range ns {
+					w.uvarint(x.ID)
+					w.string(x.Activation)
+				}
+			}
+		}
+
+		w.genes(d.Genes)
+		w.uvarint(uint64(len(d.Evaluation)))
+		for _, i := range d.Evaluation {
+			w.uvarint(uint64(i))
+		}
+		w.genes(d.Bias)
+
+		return w.Bytes()
+	}
+
+	type nodeV2 struct {
+		ID         uint64 `json:"id"`
+		Activation string `json:"activation"`
+	}
+
+	nodesV2 := func(ns []nodeData) []nodeV2 {
+		xs := make([]nodeV2, len(ns))
+		for i, x := range ns {
+			xs[i] = nodeV2{ID: x.ID, Activation: x.Activation}
+		}
+
+		return xs
+	}
+
+	jsonGenomes := map[int]interface{}{
+		1: map[string]interface{}{
+			"version":    1,
+			"id":         d.ID,
+			"fitness":    d.Fitness,
+			"activation": ActivateSigmoid,
+			"inputs":     d.Inputs,
+			"outputs":    ids(d.Outputs),
+			"hidden":     ids(d.Hidden),
+			"genes":      d.Genes,
+			"evaluation": d.Evaluation,
+			"bias":       d.Bias,
+		},
+		2: map[string]interface{}{
+			"version":    2,
+			"id":         d.ID,
+			"fitness":    d.Fitness,
+			"inputs":     d.Inputs,
+			"outputs":    nodesV2(d.Outputs),
+			"hidden":     nodesV2(d.Hidden),
+			"genes":      d.Genes,
+			"evaluation": d.Evaluation,
+			"bias":       d.Bias,
+		},
+	}
+
+	for _, version := range []int{1, 2} {
+		b, err := json.Marshal(jsonGenomes[version])
+		require.NoError(t, err)
+
+		c := new(Genome)
+		require.NoError(t, json.Unmarshal(b, c), "version %d", version)
+		requireSameGenome(t, a, c)
+
+		c = new(Genome)
+		require.NoError(t, c.UnmarshalBinary(binaryGenome(version)), "version %d", version)
+		requireSameGenome(t, a, c)
+	}
+
+	// Versions from the future are rejected
+	err = new(Genome).UnmarshalBinary(binaryGenome(genomeVersion + 1))
+	require.True(t, errors.Is(err, ErrInvalidGenome))
+}
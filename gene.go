@@ -2,7 +2,6 @@ package neater
 
 import (
 	"fmt"
-	"sync/atomic"
 )
 
 const (
@@ -17,8 +16,6 @@ type (
 
 	geneOpt func(*gene)
 
-	activationFunction func(float64) float64
-
 	nodePair struct {
 		input  nodeID
 		output nodeID
@@ -30,33 +27,15 @@ type (
 		p        nodePair
 		weight   float64
 		disabled bool
-
-		activate activationFunction
-	}
-
-	genePair struct {
-		alpha *gene
-		beta  *gene
 	}
 )
 
-var (
-	innovCount                = uint64(0)
-	defaultActivationFunction = sigmoid
-	innovIDGenerator          = nextInnov
-)
-
-func nextInnov() geneID {
-	return geneID(atomic.AddUint64(&innovCount, 1))
-}
-
-func newGene(p nodePair, w float64, f activationFunction) *gene {
+func newGene(innov geneID, p nodePair, w float64) *gene {
 	g := &gene{
-		innov:    innovIDGenerator(),
+		innov:    innov,
 		p:        p,
 		weight:   w,
 		disabled: defaultDisabled,
-		activate: f,
 	}
 
 	return g
@@ -68,10 +47,7 @@ func (g *gene) copy() *gene {
 		p:        g.p,
 		weight:   g.weight,
 		disabled: g.disabled,
-		activate: g.activate,
 	}
-	c := *g
-	return &c
 }
 
 func (g *gene) equalTo(x *gene) bool {
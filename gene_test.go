@@ -8,44 +8,34 @@ import (
 
 func TestNewGene(t *testing.T) {
 	tests := []struct {
-		name     string
-		p        nodePair
-		weight   float64
-		sum      float64
-		activate activationFunction
+		name   string
+		p      nodePair
+		weight float64
+		sum    float64
 	}{
 		{
-			name:     "input",
-			p:        nodePair{1, 0},
-			weight:   defaultWeight,
-			activate: defaultActivationFunction,
+			name:   "input",
+			p:      nodePair{1, 0},
+			weight: defaultWeight,
 		},
 		{
-			name:     "output",
-			p:        nodePair{0, 1},
-			weight:   defaultWeight,
-			activate: defaultActivationFunction,
+			name:   "output",
+			p:      nodePair{0, 1},
+			weight: defaultWeight,
 		},
 		{
-			name:     "weight",
-			weight:   defaultWeight + 1,
-			activate: defaultActivationFunction,
+			name:   "weight",
+			weight: defaultWeight + 1,
 		},
 		{
-			name:     "disabled",
-			weight:   defaultWeight,
-			activate: defaultActivationFunction,
-		},
-		{
-			name:     "activate",
-			weight:   defaultWeight,
-			activate: unit,
+			name:   "disabled",
+			weight: defaultWeight,
 		},
 	}
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			g := newGene(test.p, test.weight, test.activate)
+			g := newGene(geneID(1), test.p, test.weight)
 
 			require.Equal(t, g.p, test.p)
 			require.Equal(t, g.weight, test.weight)
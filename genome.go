@@ -0,0 +1,150 @@
+package neater
+
+import (
+	"sort"
+)
+
+type (
+	// NodeKind tells the role of a node in a genome
+	NodeKind int
+
+	// Node is a read-only view of a node in a genome
+	Node struct {
+		ID   uint64
+		Kind NodeKind
+
+		// Activation is the name of the node's activation function, input
+		// nodes have none
+		Activation string
+
+		// Aggregation is the name of the function combining the node's
+		// weighted inputs, input nodes have none
+		Aggregation string
+	}
+
+	// Gene is a read-only view of a connection gene in a genome
+	Gene struct {
+		Innovation uint64
+		Input      uint64
+		Output     uint64
+		Weight     float64
+		Enabled    bool
+	}
+
+	// Genome is an immutable snapshot of an evolved organism
+	Genome struct {
+		o *organism
+	}
+)
+
+const (
+	InputNode NodeKind = iota
+	OutputNode
+	HiddenNode
+	BiasNode
+)
+
+// BiasID is the ID of the bias node
+const BiasID = uint64(biasID)
+
+func newGenome(o *organism) *Genome {
+	if o == nil {
+		return nil
+	}
+
+	return &Genome{o: o.clone()}
+}
+
+// ID returns the ID of the organism the genome was taken from
+func (g *Genome) ID() uint64 {
+	return uint64(g.o.id)
+}
+
+// Fitness returns the raw fitness of the organism the genome was taken from
+func (g *Genome) Fitness() float64 {
+	return g.o.fitness
+}
+
+// Inputs returns the IDs of the input nodes in input order
+func (g *Genome) Inputs() []uint64 {
+	return nodeIDs(g.o.inputs)
+}
+
+// Outputs returns the IDs of the output nodes in output order
+func (g *Genome) Outputs() []uint64 {
+	return nodeIDs(g.o.outputs)
+}
+
+// Nodes returns all nodes, except the bias node, ordered by ID
+func (g *Genome) Nodes() []Node {
+	kinds := make(map[nodeID]NodeKind, len(g.o.nodes))
+	for id := range g.o.nodes {
+		kinds[id] = HiddenNode
+	}
+
+	for _, id := range g.o.inputs {
+		kinds[id] = InputNode
+	}
+
+	for _, id := range g.o.outputs {
+		kinds[id] = OutputNode
+	}
+
+	nodes := make([]Node, 0, len(kinds))
+	for id, k := range kinds {
+		nodes = append(nodes, Node{ID: uint64(id), Kind: k, Activation: g.o.acts[id].name, Aggregation: g.o.aggs[id].name})
+	}
+
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].ID < nodes[j].ID
+	})
+
+	return nodes
+}
+
+// Genes returns the connection genes in innovation order
+func (g *Genome) Genes() []Gene {
+	return genes(g.o.oinnov)
+}
+
+// BiasGenes returns the genes connecting the bias node to the hidden nodes
+func (g *Genome) BiasGenes() []Gene {
+	return genes(g.o.obias)
+}
+
+// Network compiles the genome into a neural network
+func (g *Genome) Network() *Network {
+	return compile(g.o)
+}
+
+// RecurrentNetwork compiles the genome into a stateful neural network that
+// updates every node ´settle´ times per step. Recurrent genomes should be
+// evaluated with a RecurrentNetwork, a Network ignores their recurrent
+// connections.
+func (g *Genome) RecurrentNetwork(settle int) *RecurrentNetwork {
+	return newRecurrentNetwork(compile(g.o), settle)
+}
+
+func nodeIDs(ids []nodeID) []uint64 {
+	l := make([]uint64, len(ids))
+	for i, id := range ids {
+		l[i] = uint64(id)
+	}
+
+	return l
+}
+
+func genes(gs []*gene) []Gene {
+	l := make([]Gene, len(gs))
+	for i, g := range gs {
+		l[i] = Gene{
+			Innovation: uint64(g.innov),
+			Input:      uint64(g.p.input),
+			Output:     uint64(g.p.output),
+			Weight:     g.weight,
+			Enabled:    !g.disabled,
+		}
+	}
+
+	return l
+}
@@ -0,0 +1,62 @@
+package neater
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenome(t *testing.T) {
+	conf := &Configuration{
+		Inputs:            2,
+		Outputs:           1,
+		InitialBiasWeight: 0.5,
+		activation:        activation{ActivateUnit, unit},
+		aggregation:       aggregation{AggregateSum, sum},
+	}
+
+	reg := newRegistry(conf)
+	inputs, outputs := createInputsOuputs(reg, conf)
+	o := newOrganism(conf, reg, inputs, outputs)
+	o.fitness = 0.75
+
+	// Split the connection between the first input and the output
+	reg.rand = &stubRandom{ints: []int{0}}
+	o.mutateAddNode()
+
+	g := newGenome(o)
+
+	require.Equal(t, uint64(o.id), g.ID())
+	require.Equal(t, 0.75, g.Fitness())
+	require.Equal(t, []uint64{1, 2}, g.Inputs())
+	require.Equal(t, []uint64{3}, g.Outputs())
+
+	require.Equal(t, []Node{
+		{ID: 1, Kind: InputNode},
+		{ID: 2, Kind: InputNode},
+		{ID: 3, Kind: OutputNode, Activation: ActivateUnit, Aggregation: AggregateSum},
+		{ID: 4, Kind: HiddenNode, Activation: ActivateUnit, Aggregation: AggregateSum},
+	}, g.Nodes())
+
+	require.Equal(t, []Gene{
+		{Innovation: 1, Input: 1, Output: 3, Weight: 1, Enabled: false},
+		{Innovation: 2, Input: 2, Output: 3, Weight: 1, Enabled: true},
+		{Innovation: 3, Input: 1, Output: 4, Weight: 1, Enabled: true},
+		{Innovation: 4, Input: 4, Output: 3, Weight: 1, Enabled: true},
+	}, g.Genes())
+
+	require.Equal(t, []Gene{
+		{Innovation: 5, Input: BiasID, Output: 4, Weight: 0.5, Enabled: true},
+	}, g.BiasGenes())
+
+	n := g.Network()
+	require.Equal(t, 2, n.Inputs())
+	require.Equal(t, 1, n.Outputs())
+	require.Equal(t, []float64{2.5}, n.Eval([]float64{1, 1}))
+
+	// The genome is a snapshot, later mutations of the organism don't
+	// affect it
+	o.oinnov[1].weight = 2
+	require.Equal(t, float64(1), g.Genes()[1].Weight)
+	require.Equal(t, []float64{2.5}, g.Network().Eval([]float64{1, 1}))
+}
@@ -6,7 +6,10 @@ import (
 	"strings"
 )
 
-func Graph(o *organism, w io.Writer) error {
+// Graph writes the genome as a Graphviz DOT graph to ´w´
+func Graph(genome *Genome, w io.Writer) error {
+	o := genome.o
+
 	isIn := func(n nodeID, ns []nodeID) bool {
 		for _, x := range ns {
 			if n == x {
@@ -31,7 +34,7 @@ func Graph(o *organism, w io.Writer) error {
 
 	for n := range o.nodes {
 		if isIn(n, o.outputs) {
-			b.Write([]byte(fmt.Sprintf("    node%d [shape=circle, style=filled, color=deepskyblue];\n", n)))
+			b.Write([]byte(fmt.Sprintf("    node%d [shape=circle, style=filled, color=deepskyblue, xlabel=\"%s %s\"];\n", n, o.aggs[n].name, o.acts[n].name)))
 		}
 	}
 
@@ -39,7 +42,7 @@ func Graph(o *organism, w io.Writer) error {
 
 	for n := range o.nodes {
 		if !isIn(n, o.inputs) && !isIn(n, o.outputs) {
-			b.Write([]byte(fmt.Sprintf("    node%d [shape=circle, style=filled, color=moccasin];\n", n)))
+			b.Write([]byte(fmt.Sprintf("    node%d [shape=circle, style=filled, color=moccasin, xlabel=\"%s %s\"];\n", n, o.aggs[n].name, o.acts[n].name)))
 		}
 	}
 
@@ -0,0 +1,421 @@
+package neater
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+type (
+	// UnknownKeysError is returned along with the configuration when a
+	// configuration file contains keys that don't correspond to any setting.
+	// The configuration is otherwise complete, callers that don't mind
+	// unknown keys can ignore the error.
+	UnknownKeysError struct {
+		Keys []string
+	}
+
+	// iniSetter sets the setting of a Configuration corresponding to a
+	// neat-python key
+	iniSetter func(c *Configuration, v string) error
+)
+
+// neatPythonKeys maps the keys of the neat-python configuration sections to
+// the corresponding settings
+var neatPythonKeys = map[string]map[string]iniSetter{
+	"NEAT": {
+		"pop_size": func(c *Configuration, v string) error {
+			x, err := strconv.Atoi(v)
+			c.PopulationSize = x
+			c.InitialPopulationSize = x
+			return err
+		},
+	},
+	"DefaultGenome": {
+		"num_inputs":         iniInt(func(c *Configuration) *int { return &c.Inputs }),
+		"num_outputs":        iniInt(func(c *Configuration) *int { return &c.Outputs }),
+		"activation_default": iniString(func(c *Configuration) *string { return &c.ActivationFunction }),
+		"activation_mutate_rate": iniFloat(func(c *Configuration) *float64 {
+			return &c.ActivationMutationProb
+		}),
+		"activation_options": func(c *Configuration, v string) error {
+			c.ActivationOptions = strings.Fields(v)
+			return nil
+		},
+		"aggregation_default": iniString(func(c *Configuration) *string { return &c.AggregationFunction }),
+		"aggregation_mutate_rate": iniFloat(func(c *Configuration) *float64 {
+			return &c.AggregationMutationProb
+		}),
+		"aggregation_options": func(c *Configuration, v string) error {
+			c.AggregationOptions = strings.Fields(v)
+			return nil
+		},
+		"bias_init_mean":  iniFloat(func(c *Configuration) *float64 { return &c.InitialBiasWeight }),
+		"bias_init_stdev": iniFloat(func(c *Configuration) *float64 { return &c.BiasInitStdDev }),
+		"bias_init_type": func(c *Configuration, v string) error {
+			return setNEATPythonInitType(&c.BiasInit, v)
+		},
+		"conn_add_prob": iniFloat(func(c *Configuration) *float64 { return &c.ConnectNodesMutationProb }),
+		"node_add_prob": iniFloat(func(c *Configuration) *float64 { return &c.AddNodeMutationProb }),
+		"conn_delete_prob": iniFloat(func(c *Configuration) *float64 {
+			return &c.DeleteConnectionMutationProb
+		}),
+		"node_delete_prob":  iniFloat(func(c *Configuration) *float64 { return &c.DeleteNodeMutationProb }),
+		"weight_init_mean":  iniFloat(func(c *Configuration) *float64 { return &c.WeightInitMean }),
+		"weight_init_stdev": iniFloat(func(c *Configuration) *float64 { return &c.WeightInitStdDev }),
+		"weight_init_type": func(c *Configuration, v string) error {
+			return setNEATPythonInitType(&c.WeightInit, v)
+		},
+		"weight_mutate_rate": iniFloat(func(c *Configuration) *float64 { return &c.WeightMutationProb }),
+		"weight_mutate_power": iniFloat(func(c *Configuration) *float64 {
+			return &c.WeightMutationStandardDeviation
+		}),
+		"compatibility_weight_coefficient": iniFloat(func(c *Configuration) *float64 {
+			return &c.WeightDifferenceCoefficient
+		}),
+		"compatibility_disjoint_coefficient": func(c *Configuration, v string) error {
+			// neat-python doesn't distinguish between disjoint and excess
+			// genes
+			x, err := strconv.ParseFloat(v, 64)
+			c.DisjointCoefficient = x
+			c.ExcessCoefficient = x
+			return err
+		},
+		"initial_connection": func(c *Configuration, v string) error {
+			return setNEATPythonConnection(c, v)
+		},
+		"num_hidden": iniInt(func(c *Configuration) *int { return &c.InitialHiddenNodes }),
+		"feed_forward": func(c *Configuration, v string) error {
+			x, err := strconv.ParseBool(v)
+			c.Recurrent = !x
+			return err
+		},
+	},
+	"DefaultSpeciesSet": {
+		"compatibility_threshold": iniFloat(func(c *Configuration) *float64 { return &c.CompatibilityThreshold }),
+	},
+	"DefaultStagnation": {
+		"max_stagnation": iniInt(func(c *Configuration) *int { return &c.DropOffAge }),
+	},
+	"DefaultReproduction": {
+		"survival_threshold": iniFloat(func(c *Configuration) *float64 { return &c.SurvivalThreshold }),
+	},
+}
+
+func (e *UnknownKeysError) Error() string {
+	return fmt.Sprintf("unknown configuration keys: %s", strings.Join(e.Keys, ", "))
+}
+
+// DefaultConfiguration returns a configuration holding the documented default
+// of every setting. Inputs and Outputs have no defaults.
+func DefaultConfiguration() *Configuration {
+	return &Configuration{
+		WeightMutationProb:              0.8,
+		WeightMutationPower:             2.5,
+		WeightMutationStandardDeviation: 0.5,
+		AddNodeMutationProb:             0.5,
+		ConnectNodesMutationProb:        0.5,
+		PopulationThreshold:             32,
+		SettleIterations:                1,
+		MaxPopulationSize:               64,
+		PopulationSize:                  150,
+		DisjointCoefficient:             2.0,
+		ExcessCoefficient:               2.0,
+		WeightDifferenceCoefficient:     2.0,
+		CompatibilityThreshold:          6.0,
+		CompatibilityModifier:           0.1,
+		DropOffAge:                      100,
+		SurvivalThreshold:               1.0,
+		Selection:                       SelectUniform,
+		TournamentSize:                  2,
+		MutationPower:                   2.5,
+		InitialPopulationSize:           8,
+		InitialConnection:               ConnectFull,
+		WeightInit:                      WeightInitConstant,
+		ActivationFunction:              ActivateSigmoid,
+		AggregationFunction:             AggregateSum,
+		NormalizaDistanceThreshold:      20,
+		Workers:                         1,
+	}
+}
+
+// LoadConfiguration reads the configuration file ´path´. Files ending in
+// .json are read as JSON, files ending in .yaml or .yml as YAML and anything
+// else as a neat-python configuration file.
+func LoadConfiguration(path string) (*Configuration, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return ReadJSONConfiguration(f)
+	case ".yaml", ".yml":
+		return ReadYAMLConfiguration(f)
+	default:
+		return ReadNEATPythonConfiguration(f)
+	}
+}
+
+// ReadJSONConfiguration reads a JSON configuration. Omitted settings are
+// given their defaults.
+func ReadJSONConfiguration(r io.Reader) (*Configuration, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(b, &keys); err != nil {
+		return nil, err
+	}
+
+	c := DefaultConfiguration()
+	if err := json.Unmarshal(b, c); err != nil {
+		return nil, err
+	}
+
+	return c, unknownKeys(keys)
+}
+
+// ReadYAMLConfiguration reads a YAML configuration. Omitted settings are
+// given their defaults.
+func ReadYAMLConfiguration(r io.Reader) (*Configuration, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]interface{})
+	if err := yaml.Unmarshal(b, &keys); err != nil {
+		return nil, err
+	}
+
+	c := DefaultConfiguration()
+	if err := yaml.Unmarshal(b, c); err != nil {
+		return nil, err
+	}
+
+	return c, unknownKeys(keys)
+}
+
+// ReadNEATPythonConfiguration reads the [NEAT], [DefaultGenome],
+// [DefaultSpeciesSet], [DefaultStagnation] and [DefaultReproduction] sections
+// of a neat-python configuration file. Settings that aren't part of the file
+// are given their defaults, keys without a corresponding setting are reported
+// as ´section.key´.
+func ReadNEATPythonConfiguration(r io.Reader) (*Configuration, error) {
+	sections, err := readINI(r)
+	if err != nil {
+		return nil, err
+	}
+
+	c := DefaultConfiguration()
+	unknown := make([]string, 0)
+
+	for name, section := range sections {
+		for k, v := range section {
+			set, ok := neatPythonKeys[name][k]
+			if !ok {
+				unknown = append(unknown, name+"."+k)
+				continue
+			}
+
+			if err := set(c, v); err != nil {
+				return nil, fmt.Errorf("[%s] %s: %w", name, k, err)
+			}
+		}
+	}
+
+	setNEATPythonWeightInit(c, sections["DefaultGenome"])
+
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return c, &UnknownKeysError{Keys: unknown}
+	}
+
+	return c, nil
+}
+
+// setNEATPythonWeightInit completes the weight and bias initialization once
+// every key of the [DefaultGenome] section ´genome´ is set, as the keys depend
+// on each other. neat-python draws the initial weights from a normal
+// distribution unless weight_init_type or bias_init_type says otherwise, and
+// draws uniform weights within two standard deviations of the mean.
+func setNEATPythonWeightInit(c *Configuration, genome map[string]string) {
+	_, mean := genome["weight_init_mean"]
+	_, stdev := genome["weight_init_stdev"]
+	if _, ok := genome["weight_init_type"]; !ok && (mean || stdev) {
+		c.WeightInit = WeightInitNormal
+	}
+
+	if c.WeightInit == WeightInitUniform {
+		c.WeightInitMin = c.WeightInitMean - 2*c.WeightInitStdDev
+		c.WeightInitMax = c.WeightInitMean + 2*c.WeightInitStdDev
+	}
+
+	if c.BiasInit == WeightInitUniform {
+		c.BiasInitMin = c.InitialBiasWeight - 2*c.BiasInitStdDev
+		c.BiasInitMax = c.InitialBiasWeight + 2*c.BiasInitStdDev
+	}
+}
+
+// setNEATPythonInitType sets the distribution ´init´ from a neat-python
+// weight_init_type or bias_init_type setting
+func setNEATPythonInitType(init *string, v string) error {
+	switch v {
+	case "gaussian", "normal":
+		*init = WeightInitNormal
+	case "uniform":
+		*init = WeightInitUniform
+	default:
+		return fmt.Errorf("unsupported initialization %q", v)
+	}
+
+	return nil
+}
+
+// setNEATPythonConnection sets the initial connection from the neat-python
+// initial_connection setting. The full and partial connections are supported
+// without direct connections only, as neat-python has them when the initial
+// organisms have no hidden nodes.
+func setNEATPythonConnection(c *Configuration, v string) error {
+	f := strings.Fields(v)
+	if len(f) == 0 {
+		return fmt.Errorf("missing initial connection")
+	}
+
+	switch f[0] {
+	case "unconnected":
+		c.InitialConnection = ConnectNone
+	case "fs_neat", "fs_neat_nohidden":
+		c.InitialConnection = ConnectFSNEAT
+	case "full", "full_nodirect":
+		c.InitialConnection = ConnectFull
+	case "partial", "partial_nodirect":
+		if len(f) != 2 {
+			return fmt.Errorf("%s requires a connection probability", f[0])
+		}
+
+		p, err := strconv.ParseFloat(f[1], 64)
+		if err != nil {
+			return err
+		}
+
+		c.InitialConnection = ConnectRandom
+		c.InitialConnectionProb = p
+	default:
+		return fmt.Errorf("unsupported initial connection %q", v)
+	}
+
+	return nil
+}
+
+// unknownKeys returns an *UnknownKeysError listing the keys of ´keys´ that
+// don't correspond to any setting, or nil if there are none
+func unknownKeys(keys interface{}) error {
+	known := make(map[string]bool)
+	t := reflect.TypeOf(Configuration{})
+	for i := 0; i < t.NumField(); i++ {
+		if tag := t.Field(i).Tag.Get("json"); tag != "" && tag != "-" {
+			known[tag] = true
+		}
+	}
+
+	unknown := make([]string, 0)
+	for _, k := range reflect.ValueOf(keys).MapKeys() {
+		if !known[k.String()] {
+			unknown = append(unknown, k.String())
+		}
+	}
+
+	if len(unknown) == 0 {
+		return nil
+	}
+
+	sort.Strings(unknown)
+
+	return &UnknownKeysError{Keys: unknown}
+}
+
+// readINI reads the sections of an INI file as read by Python's configparser.
+// Keys are lower case, lines starting with whitespace continue the value of
+// the previous key.
+func readINI(r io.Reader) (map[string]map[string]string, error) {
+	sections := make(map[string]map[string]string)
+
+	var section map[string]string
+	var key string
+
+	s := bufio.NewScanner(r)
+	for line := 1; s.Scan(); line++ {
+		text := s.Text()
+		trimmed := strings.TrimSpace(text)
+
+		switch {
+		case trimmed == "" || trimmed[0] == '#' || trimmed[0] == ';':
+			continue
+		case text[0] == ' ' || text[0] == '\t':
+			if key == "" {
+				return nil, fmt.Errorf("line %d: unexpected continuation", line)
+			}
+			section[key] += "\n" + trimmed
+		case trimmed[0] == '[' && trimmed[len(trimmed)-1] == ']':
+			name := strings.TrimSpace(trimmed[1 : len(trimmed)-1])
+			if _, ok := sections[name]; !ok {
+				sections[name] = make(map[string]string)
+			}
+			section = sections[name]
+			key = ""
+		default:
+			if section == nil {
+				return nil, fmt.Errorf("line %d: key outside of a section", line)
+			}
+
+			i := strings.IndexAny(trimmed, "=:")
+			if i < 0 {
+				return nil, fmt.Errorf("line %d: expected key = value", line)
+			}
+
+			key = strings.ToLower(strings.TrimSpace(trimmed[:i]))
+			section[key] = strings.TrimSpace(trimmed[i+1:])
+		}
+	}
+
+	return sections, s.Err()
+}
+
+func iniInt(f func(*Configuration) *int) iniSetter {
+	return func(c *Configuration, v string) error {
+		x, err := strconv.Atoi(v)
+		*f(c) = x
+		return err
+	}
+}
+
+func iniFloat(f func(*Configuration) *float64) iniSetter {
+	return func(c *Configuration, v string) error {
+		x, err := strconv.ParseFloat(v, 64)
+		*f(c) = x
+		return err
+	}
+}
+
+func iniString(f func(*Configuration) *string) iniSetter {
+	return func(c *Configuration, v string) error {
+		*f(c) = v
+		return nil
+	}
+}
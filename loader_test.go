@@ -0,0 +1,196 @@
+package neater
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadConfiguration(t *testing.T) {
+	expect := DefaultConfiguration()
+	expect.Inputs = 2
+	expect.Outputs = 1
+	expect.SurvivalThreshold = 0.5
+
+	tests := []struct {
+		name    string
+		read    func(string) (*Configuration, error)
+		input   string
+		unknown []string
+	}{
+		{
+			name:  "json",
+			read:  func(s string) (*Configuration, error) { return ReadJSONConfiguration(strings.NewReader(s)) },
+			input: `{"inputs": 2, "outputs": 1, "survival_threshold": 0.5}`,
+		},
+		{
+			name:    "json unknown keys",
+			read:    func(s string) (*Configuration, error) { return ReadJSONConfiguration(strings.NewReader(s)) },
+			input:   `{"inputs": 2, "outputs": 1, "survival_threshold": 0.5, "survival": 1, "outptus": 2}`,
+			unknown: []string{"outptus", "survival"},
+		},
+		{
+			name:  "yaml",
+			read:  func(s string) (*Configuration, error) { return ReadYAMLConfiguration(strings.NewReader(s)) },
+			input: "inputs: 2\noutputs: 1\nsurvival_threshold: 0.5\n",
+		},
+		{
+			name:    "yaml unknown keys",
+			read:    func(s string) (*Configuration, error) { return ReadYAMLConfiguration(strings.NewReader(s)) },
+			input:   "inputs: 2\noutputs: 1\nsurvival_threshold: 0.5\nselector: rank\n",
+			unknown: []string{"selector"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c, err := test.read(test.input)
+
+			var unknown *UnknownKeysError
+			if test.unknown != nil {
+				require.True(t, errors.As(err, &unknown))
+				require.Equal(t, test.unknown, unknown.Keys)
+			} else {
+				require.NoError(t, err)
+			}
+
+			require.Equal(t, expect, c)
+		})
+	}
+}
+
+func TestReadConfigurationInvalid(t *testing.T) {
+	_, err := ReadJSONConfiguration(strings.NewReader(`{"inputs": "two"}`))
+	require.Error(t, err)
+
+	_, err = ReadYAMLConfiguration(strings.NewReader("inputs: [2]"))
+	require.Error(t, err)
+
+	_, err = ReadNEATPythonConfiguration(strings.NewReader("[NEAT]\npop_size = many\n"))
+	require.Error(t, err)
+
+	_, err = ReadNEATPythonConfiguration(strings.NewReader("pop_size = 150\n"))
+	require.Error(t, err)
+
+	_, err = ReadNEATPythonConfiguration(strings.NewReader("[DefaultGenome]\ninitial_connection = partial\n"))
+	require.Error(t, err)
+}
+
+const neatPythonConfiguration = `
+# neat-python configuration of the XOR experiment
+
+[NEAT]
+fitness_criterion     = max
+fitness_threshold     = 3.9
+pop_size              = 150
+reset_on_extinction   = False
+
+[DefaultGenome]
+# node activation options
+activation_default      = sigmoid
+activation_mutate_rate  = 0.0
+activation_options      = sigmoid
+
+bias_init_mean          = 0.5
+
+compatibility_disjoint_coefficient = 1.0
+compatibility_weight_coefficient   = 0.5
+
+conn_add_prob           = 0.5
+node_add_prob           = 0.2
+conn_delete_prob        = 0.5
+node_delete_prob        = 0.2
+
+feed_forward            = True
+
+num_inputs              = 2
+num_hidden              = 0
+num_outputs             = 1
+initial_connection      = partial_nodirect 0.5
+
+weight_init_mean        = 0.0
+weight_init_stdev       = 1.0
+weight_mutate_power     = 0.5
+weight_mutate_rate      = 0.8
+
+[DefaultSpeciesSet]
+compatibility_threshold = 3.0
+
+[DefaultStagnation]
+species_fitness_func = max
+max_stagnation       = 20
+
+[DefaultReproduction]
+elitism            = 2
+survival_threshold = 0.2
+`
+
+func TestReadNEATPythonConfiguration(t *testing.T) {
+	c, err := ReadNEATPythonConfiguration(strings.NewReader(neatPythonConfiguration))
+
+	var unknown *UnknownKeysError
+	require.True(t, errors.As(err, &unknown))
+	require.Equal(t, []string{
+		"DefaultReproduction.elitism",
+		"DefaultStagnation.species_fitness_func",
+		"NEAT.fitness_criterion",
+		"NEAT.fitness_threshold",
+		"NEAT.reset_on_extinction",
+	}, unknown.Keys)
+
+	expect := DefaultConfiguration()
+	expect.Inputs = 2
+	expect.Outputs = 1
+	expect.PopulationSize = 150
+	expect.InitialPopulationSize = 150
+	expect.ActivationFunction = ActivateSigmoid
+	expect.ActivationOptions = []string{ActivateSigmoid}
+	expect.InitialBiasWeight = 0.5
+	expect.DisjointCoefficient = 1.0
+	expect.ExcessCoefficient = 1.0
+	expect.WeightDifferenceCoefficient = 0.5
+	expect.ConnectNodesMutationProb = 0.5
+	expect.AddNodeMutationProb = 0.2
+	expect.DeleteConnectionMutationProb = 0.5
+	expect.DeleteNodeMutationProb = 0.2
+	expect.WeightMutationStandardDeviation = 0.5
+	expect.WeightMutationProb = 0.8
+	expect.CompatibilityThreshold = 3.0
+	expect.DropOffAge = 20
+	expect.SurvivalThreshold = 0.2
+	expect.InitialConnection = ConnectRandom
+	expect.InitialConnectionProb = 0.5
+	expect.WeightInit = WeightInitNormal
+	expect.WeightInitStdDev = 1.0
+
+	require.Equal(t, expect, c)
+}
+
+func TestReadNEATPythonConfigurationWeightInit(t *testing.T) {
+	c, err := ReadNEATPythonConfiguration(strings.NewReader(`
+[DefaultGenome]
+bias_init_mean    = 1.0
+bias_init_stdev   = 0.5
+bias_init_type    = uniform
+weight_init_type  = uniform
+weight_init_mean  = 1.0
+weight_init_stdev = 0.5
+`))
+	require.NoError(t, err)
+	require.Equal(t, 1.0, c.InitialBiasWeight)
+	require.Equal(t, 0.5, c.BiasInitStdDev)
+	require.Equal(t, WeightInitUniform, c.BiasInit)
+	require.Equal(t, 0.0, c.BiasInitMin)
+	require.Equal(t, 2.0, c.BiasInitMax)
+	require.Equal(t, WeightInitUniform, c.WeightInit)
+	require.Equal(t, 0.0, c.WeightInitMin)
+	require.Equal(t, 2.0, c.WeightInitMax)
+
+	_, err = ReadNEATPythonConfiguration(strings.NewReader(`
+[DefaultGenome]
+weight_init_type = cauchy
+`))
+	require.Error(t, err)
+}
@@ -1,17 +1,14 @@
 package neater
 
-import (
-	"math"
-	"math/rand"
-)
-
-var (
-	defaultRandIntn = rand.Intn
-	randIntn        = defaultRandIntn
-
-	defaultRandFloat64 = rand.Float64
-	randFloat64        = defaultRandFloat64
-)
+// random is the source of every stochastic decision made by a Neat instance,
+// it's satisfied by *rand.Rand
+type random interface {
+	Random
+
+	// NormFloat64 returns a normally distributed number with mean 0 and
+	// standard deviation 1
+	NormFloat64() float64
+}
 
 func min(a, b int) int {
 	if a < b {
@@ -28,11 +25,3 @@ func max(a, b int) int {
 
 	return b
 }
-
-func unit(x float64) float64 {
-	return x
-}
-
-func sigmoid(x float64) float64 {
-	return float64(1) / (float64(1) + math.Exp(10*x))
-}
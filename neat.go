@@ -1,64 +1,187 @@
 package neater
 
 import (
-	"fmt"
+	"math"
 	"sort"
+	"sync"
+	"time"
 )
 
 type (
-	Stats struct {
-		Iterations int
-		NbrSpecies int
-
-		BestSpecies  *species
-		BestOrganism *organism
+	Neat struct {
+		conf      *Configuration
+		reg       *registry
+		species   []*species
+		inputs    []nodeID
+		outputs   []nodeID
+		stats     Stats
+		history   []Stats
+		reporters []Reporter
+
+		// bestSpecies is the species holding the best organism of the
+		// current generation
+		bestSpecies *species
+		// best is the best organism of the current generation
+		best *organism
+		// fittest is a copy of the best organism found since the start, as
+		// it was when it reached BestFitness
+		fittest *organism
+
+		// pruning holds the state of phased pruning
+		pruning pruning
 	}
 
-	Neat struct {
-		conf    *Configuration
-		species []*species
-		inputs  []nodeID
-		outputs []nodeID
-		stats   Stats
+	// pruning tracks the phases of phased pruning, see PhasedPruning
+	pruning struct {
+		// simplifying tells whether the population is in a simplifying
+		// phase
+		simplifying bool
+		// floor is the mean complexity at the end of the last simplifying
+		// phase, or of the initial population
+		floor float64
+		// lowest is the lowest mean complexity of the current simplifying
+		// phase, reached in iteration lowered
+		lowest  float64
+		lowered int
 	}
 )
 
+// NewNeat creates a new population from the configuration ´c´, an invalid
+// configuration is reported as a ValidationError. The population works on a
+// copy of ´c´, which can be reused once NewNeat returns.
 func NewNeat(c *Configuration) (*Neat, error) {
-	switch c.ActivationFunction {
-	case ActivateSigmoid:
-		c.activate = sigmoid
-	case ActivateUnit:
-		c.activate = unit
-	default:
-		panic("unknown activation function")
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+
+	// Resolving fills in defaults, don't write them to the caller's
+	// configuration which may be shared with other populations
+	conf := *c
+	if err := conf.resolve(); err != nil {
+		return nil, err
 	}
 
 	n := &Neat{
-		conf:    c,
-		species: make([]*species, 0, c.MaxPopulationSize),
+		conf:    &conf,
+		reg:     newRegistry(&conf),
+		species: make([]*species, 0, conf.MaxPopulationSize),
+		stats: Stats{
+			BestFitness: math.Inf(-1),
+		},
 	}
 
 	n.inputs = make([]nodeID, n.conf.Inputs)
 	for i := range n.inputs {
-		n.inputs[i] = nodeIDGenerator()
+		n.inputs[i] = n.reg.nextNodeID()
 	}
 	n.outputs = make([]nodeID, n.conf.Outputs)
 	for i := range n.outputs {
-		n.outputs[i] = nodeIDGenerator()
+		n.outputs[i] = n.reg.nextNodeID()
+	}
+
+	hidden := make([]nodeID, n.conf.InitialHiddenNodes)
+	for i := range hidden {
+		hidden[i] = n.reg.nextNodeID()
 	}
 
-	n.species = append(n.species, newSpecies(n.conf, n.inputs, n.outputs))
+	s := newSpecies(n.conf, n.reg, n.inputs, n.outputs, withHiddenLayer(hidden))
+	n.species = append(n.species, s)
+
+	for _, o := range s.population {
+		n.pruning.floor += float64(o.complexity())
+	}
+	n.pruning.floor /= float64(len(s.population))
 
 	return n, nil
 }
 
-func (n *Neat) BestOrganism() *organism {
-	return n.stats.BestOrganism
+// AddReporter attaches a Reporter that is notified about the progress of the
+// training
+func (n *Neat) AddReporter(r Reporter) {
+	n.reporters = append(n.reporters, r)
+}
+
+// Best returns the genome of the best organism of the last generation, or nil
+// if no generation has been trained
+func (n *Neat) Best() *Genome {
+	return newGenome(n.best)
+}
+
+// Stats returns the statistics of the last generation
+func (n *Neat) Stats() Stats {
+	return n.stats
+}
+
+// History returns the statistics of every generation since the start
+func (n *Neat) History() []Stats {
+	h := make([]Stats, len(n.history))
+	copy(h, n.history)
+
+	return h
+}
+
+// evaluate calculates the fitness of every organism in the population. The
+// organisms are evaluated concurrently by Workers goroutines, each organism is
+// given its own Trainer and FitnessCalculator. Organisms whose evaluation
+// fails are given the lowest fitness of the population.
+func (n *Neat) evaluate(tf TrainerFactory, cf FitnessCalculatorFactory) {
+	organisms := make([]*organism, 0, n.conf.PopulationSize)
+	for _, s := range n.species {
+		organisms = append(organisms, s.population...)
+	}
+
+	errs := make([]error, len(organisms))
+	jobs := make(chan int)
+
+	wg := new(sync.WaitGroup)
+	for i := 0; i < max(1, n.conf.Workers); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				errs[j] = organisms[j].evaluate(tf, cf)
+			}
+		}()
+	}
+
+	for i := range organisms {
+		jobs <- i
+	}
+
+	close(jobs)
+	wg.Wait()
+
+	n.stats.Evaluations += len(organisms)
+
+	floor := math.Inf(1)
+	for i, o := range organisms {
+		if errs[i] == nil {
+			floor = math.Min(floor, o.fitness)
+		}
+	}
+
+	if math.IsInf(floor, 1) {
+		// Every evaluation failed
+		floor = 0
+	}
+
+	for i, err := range errs {
+		if err == nil {
+			continue
+		}
+
+		organisms[i].fitness = floor
+		for _, r := range n.reporters {
+			r.EvaluationError(err)
+		}
+	}
 }
 
 func (n *Neat) train(tf TrainerFactory, cf FitnessCalculatorFactory) {
+	n.evaluate(tf, cf)
+
 	for _, s := range n.species {
-		s.train(tf, cf)
+		s.rank()
 	}
 
 	// Sort the species according to their most fit organism
@@ -66,13 +189,169 @@ func (n *Neat) train(tf TrainerFactory, cf FitnessCalculatorFactory) {
 		return n.species[i].champ.fitness > n.species[j].champ.fitness
 	})
 
-	n.stats.BestSpecies = n.species[0]
-	n.stats.BestOrganism = n.stats.BestSpecies.champ
+	n.bestSpecies = n.species[0]
+	n.best = n.bestSpecies.champ
+
+	n.stats.sample(n.species)
+
+	for _, s := range n.species {
+		s.truncate()
+	}
+
+	if n.best.fitness > n.stats.BestFitness {
+		n.stats.BestFitness = n.best.fitness
+		n.fittest = n.best.clone()
+		n.stats.Improved = n.stats.Iterations
+
+		for _, r := range n.reporters {
+			r.NewChampion(&n.stats)
+		}
+	}
+
+	n.normalize()
+}
+
+// normalize calculates the adjusted fitness of every organism
+func (n *Neat) normalize() {
+	floor := math.Inf(1)
+	for _, s := range n.species {
+		for _, o := range s.population {
+			floor = math.Min(floor, o.fitness)
+		}
+	}
+
+	for _, s := range n.species {
+		s.normalize(floor)
+	}
+}
+
+// prune switches between the complexifying and simplifying phases of phased
+// pruning according to the mean complexity of the evaluated generation
+func (n *Neat) prune() {
+	if !n.conf.PhasedPruning {
+		return
+	}
+
+	p := &n.pruning
+	c := n.stats.Complexity.Mean
+
+	if !p.simplifying {
+		if c > p.floor+n.conf.PruningThreshold {
+			p.simplifying = true
+			p.lowest = c
+			p.lowered = n.stats.Iterations
+		}
+	} else if c < p.lowest {
+		p.lowest = c
+		p.lowered = n.stats.Iterations
+	} else if n.stats.Iterations-p.lowered >= n.conf.PruningStagnation {
+		p.simplifying = false
+		p.floor = c
+	}
+
+	n.stats.Simplifying = p.simplifying
+}
+
+// cull removes species that have not improved for DropOffAge generations. The
+// species holding the best organism is always spared.
+func (n *Neat) cull() {
+	n.remove(func(s *species) bool {
+		return s != n.bestSpecies && s.stagnant()
+	})
+}
+
+// remove removes the species for which ´extinct´ returns true
+func (n *Neat) remove(extinct func(*species) bool) {
+	species := n.species[:0]
+	for _, s := range n.species {
+		if extinct(s) {
+			n.extinct(s)
+			continue
+		}
+
+		species = append(species, s)
+	}
+
+	// Don't hold on to the extinct species
+	for i := len(species); i < len(n.species); i++ {
+		n.species[i] = nil
+	}
+
+	n.species = species
+}
+
+// extinct records that a species has gone extinct
+func (n *Neat) extinct(s *species) {
+	n.stats.Extinct++
+	n.stats.TotalExtinct++
+
+	for _, r := range n.reporters {
+		r.SpeciesExtinct(uint64(s.id))
+	}
+}
+
+// allocateOffspring splits PopulationSize organisms across the species in
+// proportion to their adjusted fitness. The species holding the best organism
+// is always allotted at least one organism so that the champion survives.
+func (n *Neat) allocateOffspring() {
+	var total float64
+	for _, s := range n.species {
+		total += s.fitness()
+	}
+
+	size := n.conf.PopulationSize
+
+	// Hand out the whole part of each species' share and keep track of the
+	// fractional parts
+	fractions := make([]float64, len(n.species))
+	allotted := 0
+	for i, s := range n.species {
+		share := float64(size) / float64(len(n.species))
+		if total > 0 {
+			share = float64(size) * s.fitness() / total
+		}
+
+		s.offspring = int(share)
+		fractions[i] = share - float64(s.offspring)
+		allotted += s.offspring
+	}
+
+	// Hand out what remains to the species with the largest fractional parts
+	order := make([]int, len(n.species))
+	for i := range order {
+		order[i] = i
+	}
+
+	sort.SliceStable(order, func(i, j int) bool {
+		return fractions[order[i]] > fractions[order[j]]
+	})
+
+	for i := 0; allotted < size; i = (i + 1) % len(order) {
+		n.species[order[i]].offspring++
+		allotted++
+	}
+
+	// Make sure that the champion survives
+	if best := n.bestSpecies; best.offspring == 0 {
+		largest := n.species[0]
+		for _, s := range n.species {
+			if s.offspring > largest.offspring {
+				largest = s
+			}
+		}
+
+		largest.offspring--
+		best.offspring++
+	}
 }
 
 func (n *Neat) adjustPopulationSize() {
 	// Adjust the population according to the SurvivalThreshold
 	if len(n.species) > n.conf.MaxPopulationSize {
+		for _, s := range n.species[n.conf.MaxPopulationSize:] {
+			n.extinct(s)
+		}
+
 		n.species = n.species[:n.conf.MaxPopulationSize]
 	}
 }
@@ -82,7 +361,7 @@ func (n *Neat) mutate() []*organism {
 
 	// Mutate & mate organisms
 	for _, s := range n.species {
-		rejects := s.mutate()
+		rejects := s.mutate(n.pruning.simplifying)
 		if rejects != nil {
 			rejected = append(rejected, rejects...)
 		}
@@ -105,10 +384,14 @@ func (n *Neat) handleRejected(rejected []*organism) {
 
 		if !inserted {
 			// Couldn't find a suitable species for organism, time to create a new species
-
-			s := newSpecies(n.conf, n.inputs, n.outputs)
+			s := newCleanSpecies(n.conf, n.reg)
 			s.add(o)
+			s.choseRepresentative()
 			n.species = append(n.species, s)
+
+			for _, r := range n.reporters {
+				r.SpeciesCreated(uint64(s.id))
+			}
 		}
 	}
 }
@@ -119,42 +402,57 @@ func (n *Neat) mate() {
 	}
 }
 
+// removeEmpty removes species that no longer have any organisms, either
+// because they weren't allotted any offspring or because all of their
+// organisms were rejected
+func (n *Neat) removeEmpty() {
+	n.remove(func(s *species) bool {
+		return len(s.population) == 0
+	})
+}
+
 func (n *Neat) Train(tf TrainerFactory, cf FitnessCalculatorFactory) float64 {
+	start := time.Now()
+	defer func() {
+		n.stats.Elapsed += time.Since(start)
+	}()
 
 	n.stats.Iterations++
+	n.stats.Extinct = 0
+
+	for _, r := range n.reporters {
+		r.GenerationStart(n.stats.Iterations)
+	}
 
 	n.train(tf, cf)
 
+	n.prune()
+
+	n.cull()
+
 	n.adjustPopulationSize()
 
-	n.stats.NbrSpecies = len(n.species)
+	n.allocateOffspring()
 
-	n.printStats()
+	n.mate()
+
+	n.removeEmpty()
+
+	n.reg.advance()
 
 	rejected := n.mutate()
 
 	n.handleRejected(rejected)
 
-	n.mate()
+	n.removeEmpty()
 
-	return n.stats.BestOrganism.fitness
-}
+	n.stats.NbrSpecies = len(n.species)
 
-func (n *Neat) printStats() {
-	fmt.Print("\033[2J")
-	fmt.Printf("---General--------\n")
-	fmt.Printf("Iterations:      %10d\n", n.stats.Iterations)
-	fmt.Printf("NbrSpecies:      %10d\n", n.stats.NbrSpecies)
+	n.history = append(n.history, n.stats)
 
-	fmt.Printf("---Top Species----\n")
-	fmt.Printf("ID:              %10d\n", n.stats.BestSpecies.id)
-	fmt.Printf("Generation:      %10d\n", n.stats.BestSpecies.generation)
-	fmt.Printf("Population size: %10d\n", len(n.stats.BestSpecies.population))
+	for _, r := range n.reporters {
+		r.GenerationEnd(&n.stats)
+	}
 
-	fmt.Printf("---Top Organism---\n")
-	fmt.Printf("ID:              %10d\n", n.stats.BestOrganism.id)
-	fmt.Printf("Fitness:         %10f\n", n.stats.BestOrganism.fitness)
-	fmt.Printf("Node count:      %10d\n", len(n.stats.BestOrganism.nodes))
-	fmt.Printf("Gene count:      %10d\n", len(n.stats.BestOrganism.oinnov))
-	fmt.Printf("\n\n")
+	return n.best.fitness
 }
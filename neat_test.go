@@ -0,0 +1,393 @@
+package neater
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAllocateOffspring(t *testing.T) {
+	tests := []struct {
+		name           string
+		populationSize int
+		fitness        [][]float64
+		expect         []int
+	}{
+		{
+			name:           "proportional",
+			populationSize: 8,
+			fitness: [][]float64{
+				{3, 3},
+				{1, 1},
+			},
+			expect: []int{6, 2},
+		},
+		{
+			name:           "equal when no fitness",
+			populationSize: 9,
+			fitness: [][]float64{
+				{0},
+				{0},
+				{0},
+			},
+			expect: []int{3, 3, 3},
+		},
+		{
+			name:           "remainder to largest fraction",
+			populationSize: 10,
+			fitness: [][]float64{
+				{2},
+				{1},
+			},
+			expect: []int{7, 3},
+		},
+		{
+			name:           "champion always survives",
+			populationSize: 4,
+			fitness: [][]float64{
+				{1},
+				{100, 100, 100},
+			},
+			expect: []int{1, 3},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			conf := &Configuration{PopulationSize: test.populationSize}
+			n := &Neat{conf: conf, reg: newRegistry(conf)}
+
+			for _, fitness := range test.fitness {
+				s := newCleanSpecies(conf, n.reg)
+				for _, f := range fitness {
+					o := &organism{adjusted: f}
+					s.add(o)
+				}
+				n.species = append(n.species, s)
+			}
+			n.bestSpecies = n.species[0]
+
+			n.allocateOffspring()
+
+			total := 0
+			for i, s := range n.species {
+				require.Equal(t, test.expect[i], s.offspring)
+				total += s.offspring
+			}
+			require.Equal(t, test.populationSize, total)
+		})
+	}
+}
+
+func TestIndependentIDs(t *testing.T) {
+	conf := DefaultConfiguration()
+	conf.Inputs = 2
+	conf.Outputs = 1
+	conf.InitialPopulationSize = 2
+
+	a, err := NewNeat(conf)
+	require.NoError(t, err)
+
+	b, err := NewNeat(conf)
+	require.NoError(t, err)
+
+	require.Equal(t, a.inputs, b.inputs)
+	require.Equal(t, a.outputs, b.outputs)
+	require.Equal(t, a.species[0].id, b.species[0].id)
+	require.Equal(t, a.species[0].population[0].id, b.species[0].population[0].id)
+	require.Equal(t, a.reg.innovs.current(), b.reg.innovs.current())
+}
+
+func TestNewNeatSharedConfiguration(t *testing.T) {
+	conf := xorConfiguration(1)
+	conf.PopulationSize = 0
+	expect := *conf
+
+	// Populations created concurrently from the same configuration don't
+	// write to it
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			n, err := NewNeat(conf)
+			require.NoError(t, err)
+			require.Equal(t, conf.InitialPopulationSize, n.conf.PopulationSize)
+			require.True(t, n.reg.conf != conf)
+			require.True(t, n.reg.conf == n.conf)
+		}()
+	}
+
+	wg.Wait()
+
+	require.Equal(t, expect, *conf)
+}
+
+type xorTrainer struct {
+	n int
+}
+
+func (t *xorTrainer) Next() ([]float64, bool) {
+	if t.n == 4 {
+		return nil, false
+	}
+
+	input := []float64{float64(t.n / 2), float64(t.n % 2)}
+	t.n++
+
+	return input, true
+}
+
+func (t *xorTrainer) Reset() {
+	t.n = 0
+}
+
+type xorFitnessCalculator struct {
+	aggrErr float64
+}
+
+func (c *xorFitnessCalculator) AddResult(input, output []float64) {
+	expect := float64(0)
+	if input[0] != input[1] {
+		expect = 1
+	}
+
+	c.aggrErr += (expect - output[0]) * (expect - output[0])
+}
+
+func (c *xorFitnessCalculator) CalculateFitness() float64 {
+	return 4 - c.aggrErr
+}
+
+func (c *xorFitnessCalculator) Reset() {
+	c.aggrErr = 0
+}
+
+func xorFactories() (TrainerFactory, FitnessCalculatorFactory) {
+	tf := TrainerFactory{
+		New: func() Trainer {
+			return new(xorTrainer)
+		},
+	}
+
+	cf := FitnessCalculatorFactory{
+		New: func() FitnessCalculator {
+			return new(xorFitnessCalculator)
+		},
+	}
+
+	return tf, cf
+}
+
+func xorConfiguration(seed int64) *Configuration {
+	return &Configuration{
+		Inputs:                          2,
+		Outputs:                         1,
+		AddNodeMutationProb:             0.3,
+		ConnectNodesMutationProb:        0.5,
+		WeightMutationProb:              0.8,
+		WeightMutationPower:             2.5,
+		WeightMutationStandardDeviation: 0.5,
+		PopulationThreshold:             32,
+		MaxPopulationSize:               16,
+		PopulationSize:                  50,
+		DisjointCoefficient:             2.0,
+		ExcessCoefficient:               2.0,
+		WeightDifferenceCoefficient:     2.0,
+		CompatibilityThreshold:          6.0,
+		CompatibilityModifier:           0.1,
+		DropOffAge:                      15,
+		SurvivalThreshold:               0.5,
+		InitialPopulationSize:           8,
+		ActivationFunction:              ActivateSigmoid,
+		NormalizaDistanceThreshold:      20,
+		Seed:                            seed,
+	}
+}
+
+func TestSeed(t *testing.T) {
+	tf, cf := xorFactories()
+
+	train := func(seed int64) *organism {
+		n, err := NewNeat(xorConfiguration(seed))
+		require.NoError(t, err)
+
+		for i := 0; i < 20; i++ {
+			n.Train(tf, cf)
+		}
+
+		return n.best
+	}
+
+	a := train(1)
+	b := train(1)
+
+	require.Equal(t, a.fitness, b.fitness)
+	require.Equal(t, len(a.oinnov), len(b.oinnov))
+	for i, g := range a.oinnov {
+		require.True(t, g.equalTo(b.oinnov[i]))
+	}
+}
+
+func TestWorkers(t *testing.T) {
+	tf, cf := xorFactories()
+
+	train := func(workers int) *organism {
+		conf := xorConfiguration(1)
+		conf.Workers = workers
+
+		n, err := NewNeat(conf)
+		require.NoError(t, err)
+
+		for i := 0; i < 20; i++ {
+			n.Train(tf, cf)
+		}
+
+		return n.best
+	}
+
+	a := train(1)
+	b := train(8)
+
+	require.Equal(t, a.fitness, b.fitness)
+	require.Equal(t, len(a.oinnov), len(b.oinnov))
+	for i, g := range a.oinnov {
+		require.True(t, g.equalTo(b.oinnov[i]))
+	}
+}
+
+func TestSparseConnectionTraining(t *testing.T) {
+	tf, cf := xorFactories()
+
+	for _, connection := range []string{ConnectNone, ConnectFSNEAT, ConnectRandom} {
+		t.Run(connection, func(t *testing.T) {
+			conf := xorConfiguration(1)
+			conf.InitialConnection = connection
+			conf.InitialConnectionProb = 0.5
+
+			n, err := NewNeat(conf)
+			require.NoError(t, err)
+
+			first := n.Train(tf, cf)
+			for i := 0; i < 29; i++ {
+				n.Train(tf, cf)
+			}
+
+			// Genomes without genes in common still speciate and the
+			// population learns
+			require.LessOrEqual(t, len(n.species), n.conf.MaxPopulationSize*3/2)
+			require.Greater(t, n.best.fitness, first)
+
+			// Crossover keeps the terminal nodes whether or not they are
+			// connected
+			for _, s := range n.species {
+				for _, o := range s.population {
+					for _, id := range append(o.inputs, o.outputs...) {
+						require.True(t, o.hasNode(id), "organism %d lacks node %d", o.id, id)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestInnovationOrder(t *testing.T) {
+	tf, cf := xorFactories()
+
+	n, err := NewNeat(xorConfiguration(7))
+	require.NoError(t, err)
+
+	for i := 0; i < 20; i++ {
+		n.Train(tf, cf)
+	}
+
+	// Genes of connections made again get their original innovation number,
+	// the genomes stay sorted for crossover and distance to line them up
+	for _, s := range n.species {
+		for _, o := range s.population {
+			pairs := make(map[nodePair]bool, len(o.oinnov))
+			for i, g := range o.oinnov {
+				if i > 0 {
+					require.True(t, o.oinnov[i-1].innov < g.innov, "organism %d", o.id)
+				}
+
+				require.False(t, pairs[g.p], "organism %d connects %v twice", o.id, g.p)
+				pairs[g.p] = true
+			}
+		}
+	}
+}
+
+func TestPrune(t *testing.T) {
+	n := &Neat{
+		conf: &Configuration{
+			PhasedPruning:     true,
+			PruningThreshold:  2,
+			PruningStagnation: 2,
+		},
+		pruning: pruning{floor: 10},
+	}
+
+	tests := []struct {
+		complexity  float64
+		simplifying bool
+	}{
+		{complexity: 11, simplifying: false},
+		// Exceeds the floor by more than the threshold
+		{complexity: 12.5, simplifying: true},
+		{complexity: 12, simplifying: true},
+		{complexity: 12, simplifying: true},
+		// Hasn't decreased for two generations, 12.5 becomes the floor
+		{complexity: 12.5, simplifying: false},
+		{complexity: 14, simplifying: false},
+		{complexity: 15, simplifying: true},
+	}
+
+	for i, test := range tests {
+		n.stats.Iterations = i + 1
+		n.stats.Complexity.Mean = test.complexity
+		n.prune()
+
+		require.Equal(t, test.simplifying, n.pruning.simplifying, "iteration %d", i+1)
+		require.Equal(t, test.simplifying, n.stats.Simplifying, "iteration %d", i+1)
+	}
+
+	require.Equal(t, 12.5, n.pruning.floor)
+}
+
+func TestPhasedPruning(t *testing.T) {
+	tf, cf := xorFactories()
+
+	conf := xorConfiguration(1)
+	conf.DeleteConnectionMutationProb = 0.05
+	conf.DeleteNodeMutationProb = 0.02
+	conf.PhasedPruning = true
+	conf.PruningThreshold = 1
+	conf.PruningStagnation = 3
+
+	n, err := NewNeat(conf)
+	require.NoError(t, err)
+	require.Equal(t, 2.0, n.pruning.floor)
+
+	phases := 0
+	for i := 0; i < 60; i++ {
+		simplifying := n.pruning.simplifying
+		n.Train(tf, cf)
+
+		if n.pruning.simplifying != simplifying {
+			phases++
+		}
+	}
+
+	require.GreaterOrEqual(t, phases, 2)
+
+	// The phase survives a checkpoint
+	buf := new(bytes.Buffer)
+	require.NoError(t, n.Checkpoint(buf))
+
+	r, err := Restore(buf)
+	require.NoError(t, err)
+	require.Equal(t, n.pruning, r.pruning)
+}
@@ -0,0 +1,206 @@
+package neater
+
+type (
+	// Network is a neural network compiled from a Genome, used for
+	// inference. The nodes are laid out in evaluation order in a single
+	// slice and disabled genes are left out, so that a forward pass doesn't
+	// allocate. A Network is not safe for concurrent use, create one Network
+	// per goroutine.
+	Network struct {
+		// inputs is the number of input nodes, they occupy the first slots
+		// of values
+		inputs int
+		// outputs holds the slots of the output nodes in output order
+		outputs []int
+		// nodes holds the hidden and output nodes in evaluation order
+		nodes []netNode
+		// conns holds the enabled connections grouped by output node
+		conns []netConn
+		// values holds the output of every node
+		values []float64
+		// ins holds the weighted inputs of the node being evaluated
+		ins []float64
+		// out holds the result of the last forward pass
+		out []float64
+	}
+
+	// netNode is a hidden or output node of a Network
+	netNode struct {
+		// slot is the index of the node in Network.values
+		slot int
+		bias float64
+		// conns is the range of the node's incoming connections in
+		// Network.conns
+		conns [2]int
+		act   activationFunction
+		agg   aggregationFunction
+		// sum tells whether ´agg´ is the sum, which is computed without
+		// collecting the weighted inputs
+		sum bool
+	}
+
+	// netConn is an enabled connection of a Network
+	netConn struct {
+		// src is the slot of the input node
+		src    int
+		weight float64
+	}
+)
+
+// compile builds a Network from the organism. The nodes are evaluated in
+// topological order so that every node is evaluated after the nodes it
+// depends on. A connection closing a cycle, which only recurrent genomes
+// have, points backwards in that order and reads 0. Use a RecurrentNetwork to
+// give meaning to those connections.
+func compile(o *organism) *Network {
+	order := make([]nodeID, 0, len(o.acts))
+	for _, id := range o.nodeOrder() {
+		if _, ok := o.acts[id]; ok {
+			order = append(order, id)
+		}
+	}
+
+	slots := make(map[nodeID]int, len(o.inputs)+len(order))
+	for i, id := range o.inputs {
+		slots[id] = i
+	}
+
+	for i, id := range order {
+		slots[id] = len(o.inputs) + i
+	}
+
+	bias := make(map[nodeID]float64, len(o.obias))
+	for _, g := range o.obias {
+		bias[g.p.output] += biasOutput * g.weight
+	}
+
+	incoming := make(map[nodeID][]netConn, len(order))
+	for _, g := range o.oeval {
+		if g.disabled {
+			continue
+		}
+
+		c := netConn{src: slots[g.p.input], weight: g.weight}
+		incoming[g.p.output] = append(incoming[g.p.output], c)
+	}
+
+	n := &Network{
+		inputs:  len(o.inputs),
+		outputs: make([]int, len(o.outputs)),
+		nodes:   make([]netNode, len(order)),
+		conns:   make([]netConn, 0, len(o.oeval)),
+		values:  make([]float64, len(o.inputs)+len(order)),
+		out:     make([]float64, len(o.outputs)),
+	}
+
+	for i, id := range o.outputs {
+		n.outputs[i] = slots[id]
+	}
+
+	fanIn := 0
+	for i, id := range order {
+		start := len(n.conns)
+		n.conns = append(n.conns, incoming[id]...)
+		fanIn = max(fanIn, len(incoming[id]))
+
+		n.nodes[i] = netNode{
+			slot:  slots[id],
+			bias:  bias[id],
+			conns: [2]int{start, len(n.conns)},
+			act:   o.acts[id].f,
+			agg:   o.aggs[id].f,
+			sum:   o.aggs[id].name == AggregateSum,
+		}
+	}
+
+	n.ins = make([]float64, fanIn)
+
+	return n
+}
+
+// Inputs returns the number of inputs of the network
+func (n *Network) Inputs() int {
+	return n.inputs
+}
+
+// Outputs returns the number of outputs of the network
+func (n *Network) Outputs() int {
+	return len(n.outputs)
+}
+
+// Eval feeds ´input´ through the network and returns the output. The
+// returned slice is reused by the next call to Eval, copy it to keep it.
+func (n *Network) Eval(input []float64) []float64 {
+	n.forward(input)
+
+	for i, slot := range n.outputs {
+		n.out[i] = n.values[slot]
+	}
+
+	return n.out
+}
+
+// EvalBatch feeds each row of ´inputs´ through the network and writes the
+// result to the corresponding row of ´outputs´. ´outputs´ must have as many
+// rows as ´inputs´, each of Outputs values.
+func (n *Network) EvalBatch(inputs, outputs [][]float64) {
+	if len(outputs) != len(inputs) {
+		panic("Number of output rows must equal number of input rows")
+	}
+
+	for i, input := range inputs {
+		output := outputs[i]
+		if len(output) != len(n.outputs) {
+			panic("Length of output vector must equal number of output nodes")
+		}
+
+		n.forward(input)
+
+		for j, slot := range n.outputs {
+			output[j] = n.values[slot]
+		}
+	}
+}
+
+// forward evaluates every node of the network
+func (n *Network) forward(input []float64) {
+	if len(input) != n.inputs {
+		panic("Length of input vector must equal number of input nodes")
+	}
+
+	copy(n.values, input)
+
+	// Clear the other nodes so that backward connections read 0
+	values := n.values[n.inputs:]
+	for i := range values {
+		values[i] = 0
+	}
+
+	for i := range n.nodes {
+		x := &n.nodes[i]
+		n.values[x.slot] = n.activate(x, n.values)
+	}
+}
+
+// activate returns the output of the node ´x´ given the outputs of the other
+// nodes in ´values´
+func (n *Network) activate(x *netNode, values []float64) float64 {
+	conns := n.conns[x.conns[0]:x.conns[1]]
+
+	v := x.bias
+	if x.sum {
+		s := 0.0
+		for _, c := range conns {
+			s += values[c.src] * c.weight
+		}
+		v += s
+	} else if len(conns) > 0 {
+		ins := n.ins[:len(conns)]
+		for j, c := range conns {
+			ins[j] = values[c.src] * c.weight
+		}
+		v += x.agg(ins)
+	}
+
+	return x.act(v)
+}
@@ -0,0 +1,200 @@
+package neater
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// largeOrganism returns an organism with ´splits´ hidden nodes and a mix of
+// activation and aggregation functions
+func largeOrganism(t testing.TB, splits int) *organism {
+	conf := xorConfiguration(1)
+	conf.Inputs = 8
+	conf.Outputs = 4
+	conf.InitialBiasWeight = 0.5
+	conf.ActivationOptions = []string{ActivateSigmoid, ActivateTanh, ActivateGaussian}
+	conf.ActivationMutationProb = 0.5
+	conf.AggregationOptions = []string{AggregateSum, AggregateProduct, AggregateMax, AggregateMedian}
+	conf.AggregationMutationProb = 0.5
+	require.NoError(t, conf.Validate())
+	require.NoError(t, conf.resolve())
+
+	reg := newRegistry(conf)
+	inputs, outputs := createInputsOuputs(reg, conf)
+	o := newOrganism(conf, reg, inputs, outputs)
+
+	for len(o.acts) < conf.Outputs+splits {
+		o.mutateAddNode()
+	}
+
+	for i := 0; i < 5; i++ {
+		o.mutateWeight()
+		o.mutateActivation()
+		o.mutateAggregation()
+	}
+
+	return o
+}
+
+func TestNetworkEval(t *testing.T) {
+	tests := []struct {
+		name string
+		o    *organism
+	}{
+		{name: "evolved", o: evolvedGenome(t).o},
+		{name: "large", o: largeOrganism(t, 40)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			n := compile(test.o)
+			require.Equal(t, len(test.o.inputs), n.Inputs())
+			require.Equal(t, len(test.o.outputs), n.Outputs())
+
+			// The network gives the same results as the organism
+			r := rand.New(rand.NewSource(1))
+			input := make([]float64, n.Inputs())
+			for i := 0; i < 100; i++ {
+				for j := range input {
+					input[j] = r.Float64()*4 - 2
+				}
+
+				require.Equal(t, test.o.Eval(input), n.Eval(input))
+			}
+		})
+	}
+}
+
+func TestNetworkDisabledGenes(t *testing.T) {
+	conf := &Configuration{
+		Inputs:      2,
+		Outputs:     1,
+		activation:  activation{ActivateUnit, unit},
+		aggregation: aggregation{AggregateSum, sum},
+	}
+
+	reg := newRegistry(conf)
+	inputs, outputs := createInputsOuputs(reg, conf)
+	o := newOrganism(conf, reg, inputs, outputs)
+	o.oinnov[0].disabled = true
+
+	n := compile(o)
+	require.Len(t, n.conns, 1)
+	require.Equal(t, []float64{2}, n.Eval([]float64{1, 2}))
+}
+
+func TestNetworkEvalBatch(t *testing.T) {
+	n := compile(largeOrganism(t, 40))
+
+	r := rand.New(rand.NewSource(1))
+	inputs := make([][]float64, 20)
+	outputs := make([][]float64, len(inputs))
+	for i := range inputs {
+		inputs[i] = make([]float64, n.Inputs())
+		for j := range inputs[i] {
+			inputs[i][j] = r.Float64()*4 - 2
+		}
+		outputs[i] = make([]float64, n.Outputs())
+	}
+
+	n.EvalBatch(inputs, outputs)
+	for i, input := range inputs {
+		require.Equal(t, n.Eval(input), outputs[i])
+	}
+
+	allocs := testing.AllocsPerRun(10, func() {
+		n.EvalBatch(inputs, outputs)
+	})
+	require.Zero(t, allocs)
+
+	require.Panics(t, func() { n.EvalBatch(inputs, outputs[1:]) })
+
+	outputs[3] = outputs[3][1:]
+	require.Panics(t, func() { n.EvalBatch(inputs, outputs) })
+}
+
+// batchXORTrainer hands out the XOR inputs as a batch only
+type batchXORTrainer struct {
+	xorTrainer
+}
+
+func (t *batchXORTrainer) Next() ([]float64, bool) {
+	panic("Next called on a batch trainer")
+}
+
+func (t *batchXORTrainer) Batch() [][]float64 {
+	return [][]float64{{0, 0}, {0, 1}, {1, 0}, {1, 1}}
+}
+
+func TestEvaluateBatch(t *testing.T) {
+	tf, cf := xorFactories()
+	o := evolvedGenome(t).o
+
+	require.NoError(t, o.evaluate(tf, cf))
+	fitness := o.fitness
+
+	tf.New = func() Trainer { return new(batchXORTrainer) }
+	require.NoError(t, o.evaluate(tf, cf))
+	require.Equal(t, fitness, o.fitness)
+}
+
+func TestNetworkAllocs(t *testing.T) {
+	n := compile(largeOrganism(t, 40))
+	input := make([]float64, n.Inputs())
+
+	allocs := testing.AllocsPerRun(100, func() {
+		n.Eval(input)
+	})
+	require.Zero(t, allocs)
+}
+
+func BenchmarkOrganismEval(b *testing.B) {
+	o := largeOrganism(b, 40)
+	input := make([]float64, len(o.inputs))
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		o.Eval(input)
+	}
+}
+
+func BenchmarkNetworkEval(b *testing.B) {
+	n := compile(largeOrganism(b, 40))
+	input := make([]float64, n.Inputs())
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		n.Eval(input)
+	}
+}
+
+func BenchmarkCompile(b *testing.B) {
+	o := largeOrganism(b, 40)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		compile(o)
+	}
+}
+
+func BenchmarkNetworkEvalBatch(b *testing.B) {
+	n := compile(largeOrganism(b, 40))
+
+	inputs := make([][]float64, 100)
+	outputs := make([][]float64, len(inputs))
+	for i := range inputs {
+		inputs[i] = make([]float64, n.Inputs())
+		outputs[i] = make([]float64, n.Outputs())
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		n.EvalBatch(inputs, outputs)
+	}
+}
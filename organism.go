@@ -2,9 +2,9 @@ package neater
 
 import (
 	"fmt"
-	"math/rand"
+	"math"
+	"sort"
 	"strings"
-	"sync/atomic"
 )
 
 type (
@@ -17,6 +17,10 @@ type (
 		// conf is the global configuration
 		conf *Configuration
 
+		// reg is the registry of IDs, innovations and randomness shared by
+		// the population
+		reg *registry
+
 		// input holds input node IDs
 		inputs []nodeID
 		// output holds output node IDs
@@ -29,6 +33,13 @@ type (
 		obias []*gene
 		// nodes holds all the nodes values
 		nodes map[nodeID]float64
+		// acts holds the activation function of every hidden and output node
+		acts map[nodeID]activation
+		// aggs holds the aggregation function of every hidden and output node
+		aggs map[nodeID]aggregation
+		// ins holds the weighted inputs of every hidden and output node
+		// during evaluation
+		ins map[nodeID][]float64
 
 		// terminalNodes the set of input and output nodeIDs
 		terminalNodes map[nodeID]bool
@@ -36,32 +47,34 @@ type (
 		// strategy determines how to connect the nodes during the initial
 		// setup
 		strategy connectStrategy
+		// hidden holds the IDs of the hidden layer connected by the full
+		// strategy during the initial setup
+		hidden []nodeID
 
-		// fitness is the organism's fitness
+		// fitness is the organism's raw fitness
 		fitness float64
+
+		// adjusted is the organism's fitness shared with the rest of its
+		// species
+		adjusted float64
 	}
 
 	organismOpt func(*organism)
 )
 
-var (
-	organismCount = uint64(0)
-)
-
 const (
-	connectNone = connectStrategy(iota)
-	connectFull
+	connectFull = connectStrategy(iota)
 	connectFlow
+	connectNone
 	connectRandom
+	connectFSNEAT
 
-	defaultConnectStrategy = connectFull
+	// connectAttempts is the number of node pairs mutateConnectNodes draws
+	// before giving up
+	connectAttempts = 20
 )
 
-func nextOrganismID() organismID {
-	return organismID(atomic.AddUint64(&organismCount, 1))
-}
-
-func newCleanOrganism(conf *Configuration) *organism {
+func newCleanOrganism(conf *Configuration, reg *registry) *organism {
 	if conf.Inputs <= 0 {
 		panic("Number of inputs must be greater than 0")
 	}
@@ -73,21 +86,24 @@ func newCleanOrganism(conf *Configuration) *organism {
 	// Total number of initial nodes is #inputs + #outputs + bias
 	nNodes := conf.Inputs*conf.Outputs + 1
 	return &organism{
-		id:            nextOrganismID(),
+		id:            reg.nextOrganismID(),
 		conf:          conf,
+		reg:           reg,
 		inputs:        make([]nodeID, conf.Inputs),
 		outputs:       make([]nodeID, conf.Outputs),
 		oinnov:        make([]*gene, 0, nNodes),
 		oeval:         make([]*gene, 0, nNodes),
 		obias:         make([]*gene, 0, conf.Outputs),
 		nodes:         make(map[nodeID]float64, nNodes),
+		acts:          make(map[nodeID]activation, conf.Outputs),
+		aggs:          make(map[nodeID]aggregation, conf.Outputs),
 		terminalNodes: make(map[nodeID]bool),
-		strategy:      defaultConnectStrategy,
+		strategy:      conf.connection,
 	}
 }
 
-func newOrganism(conf *Configuration, inputs, outputs []nodeID, opts ...organismOpt) *organism {
-	o := newCleanOrganism(conf)
+func newOrganism(conf *Configuration, reg *registry, inputs, outputs []nodeID, opts ...organismOpt) *organism {
+	o := newCleanOrganism(conf, reg)
 
 	for _, opt := range opts {
 		opt(o)
@@ -105,26 +121,56 @@ func newOrganism(conf *Configuration, inputs, outputs []nodeID, opts ...organism
 	copy(o.outputs, outputs)
 	for _, id := range o.outputs {
 		o.nodes[id] = 0
+		o.acts[id] = conf.activation
+		o.aggs[id] = conf.aggregation
 		o.terminalNodes[id] = true
 	}
 
 	o.connectTerminals()
+	o.initWeights()
 
 	return o
 }
 
+// copy returns an offspring that is genetically identical to the organism
 func (o *organism) copy() *organism {
-	x := newCleanOrganism(o.conf)
+	x := o.clone()
+	x.id = o.reg.nextOrganismID()
+	x.fitness = 0
+	x.adjusted = 0
+
+	return x
+}
+
+// clone returns an exact duplicate of the organism, ID and fitness included
+func (o *organism) clone() *organism {
+	x := &organism{
+		id:       o.id,
+		conf:     o.conf,
+		reg:      o.reg,
+		inputs:   make([]nodeID, len(o.inputs)),
+		outputs:  make([]nodeID, len(o.outputs)),
+		oinnov:   make([]*gene, 0, len(o.oinnov)),
+		oeval:    make([]*gene, 0, len(o.oeval)),
+		obias:    make([]*gene, 0, len(o.obias)),
+		fitness:  o.fitness,
+		adjusted: o.adjusted,
+	}
 
 	copy(x.inputs, o.inputs)
 	copy(x.outputs, o.outputs)
 
+	// oinnov and oeval share their genes, make sure the copies do too so
+	// that mutations are reflected in the evaluation order
+	genes := make(map[*gene]*gene, len(o.oinnov))
 	for _, g := range o.oinnov {
-		x.oinnov = append(x.oinnov, g.copy())
+		c := g.copy()
+		genes[g] = c
+		x.oinnov = append(x.oinnov, c)
 	}
 
 	for _, g := range o.oeval {
-		x.oeval = append(x.oeval, g.copy())
+		x.oeval = append(x.oeval, genes[g])
 	}
 
 	for _, g := range o.obias {
@@ -136,6 +182,16 @@ func (o *organism) copy() *organism {
 		x.nodes[k] = v
 	}
 
+	x.acts = make(map[nodeID]activation, len(o.acts))
+	for k, v := range o.acts {
+		x.acts[k] = v
+	}
+
+	x.aggs = make(map[nodeID]aggregation, len(o.aggs))
+	for k, v := range o.aggs {
+		x.aggs[k] = v
+	}
+
 	x.terminalNodes = make(map[nodeID]bool, len(o.terminalNodes))
 	for k, v := range o.terminalNodes {
 		x.terminalNodes[k] = v
@@ -150,11 +206,17 @@ func (o *organism) connectTerminals() {
 	switch o.strategy {
 	case connectNone:
 	case connectFull:
-		o.connectFull()
+		if len(o.hidden) > 0 {
+			o.connectHidden()
+		} else {
+			o.connectFull()
+		}
 	case connectFlow:
 		o.connectFlow()
 	case connectRandom:
-		panic("Not implemented")
+		o.connectRandom()
+	case connectFSNEAT:
+		o.connectFSNEAT()
 	}
 }
 
@@ -163,10 +225,13 @@ func (o *organism) connectFull() {
 	// Connect input to putput
 	for _, in := range o.inputs {
 		for _, out := range o.outputs {
-			g := newGene(nodePair{in, out}, defaultWeight, o.conf.activate)
-			o.addGene(g)
+			p := nodePair{in, out}
+			g := newGene(o.reg.connect(p), p, defaultWeight)
+			o.insertGene(g)
 		}
 	}
+
+	o.sortEvaluation()
 }
 
 func (o *organism) connectFlow() {
@@ -176,138 +241,145 @@ func (o *organism) connectFlow() {
 		input := o.inputs[i%len(o.inputs)]
 		output := o.outputs[i%len(o.outputs)]
 
-		g := newGene(nodePair{input, output}, defaultWeight, o.conf.activate)
-		o.addGene(g)
+		p := nodePair{input, output}
+		g := newGene(o.reg.connect(p), p, defaultWeight)
+		o.insertGene(g)
 	}
+
+	o.sortEvaluation()
 }
 
-func (o *organism) addBias(id nodeID) {
-	// Disallow adding bias to terminal nodes
-	if o.terminalNodes[id] {
-		return
+// connectHidden connects each input node to every node of the hidden layer
+// and each node of the hidden layer to every output node
+func (o *organism) connectHidden() {
+	for _, id := range o.hidden {
+		o.addNode(id)
 	}
 
-	p := nodePair{biasID, id}
-
-	// Check that the node isn't already biased
-	for _, g := range o.obias {
-		if g.p == p {
-			return
+	for _, in := range o.inputs {
+		for _, id := range o.hidden {
+			p := nodePair{in, id}
+			o.insertGene(newGene(o.reg.connect(p), p, defaultWeight))
 		}
 	}
 
-	//g := newGene(p, defaultWeight, o.conf.activate)
-	g := newGene(p, o.conf.InitialBiasWeight, o.conf.activate)
-	o.obias = append(o.obias, g)
-}
+	for _, id := range o.hidden {
+		for _, out := range o.outputs {
+			p := nodePair{id, out}
+			o.insertGene(newGene(o.reg.connect(p), p, defaultWeight))
+		}
+	}
 
-func (o *organism) addNode(id nodeID) {
-	o.nodes[id] = 0
-	o.addBias(id)
+	o.sortEvaluation()
 }
 
-func (o *organism) addGene(g *gene) {
-	if _, ok := o.nodes[g.p.input]; !ok {
-		panic(fmt.Sprintf("node not found %d", g.p.input))
-	}
+// connectRandom connects each input node to each output node with
+// probability InitialConnectionProb
+func (o *organism) connectRandom() {
+	for _, in := range o.inputs {
+		for _, out := range o.outputs {
+			if o.reg.rand.Float64() >= o.conf.InitialConnectionProb {
+				continue
+			}
 
-	if _, ok := o.nodes[g.p.output]; !ok {
-		panic(fmt.Sprintf("node not found %d", g.p.output))
+			p := nodePair{in, out}
+			o.insertGene(newGene(o.reg.connect(p), p, defaultWeight))
+		}
 	}
 
-	g = g.copy()
+	o.sortEvaluation()
+}
 
-	// Make note that the nodes are connected
-	//o.connect(g.p)
+// connectFSNEAT connects a single random input node to a random output node,
+// as in FS-NEAT, so that evolution selects the relevant inputs
+func (o *organism) connectFSNEAT() {
+	in := o.inputs[o.reg.rand.Intn(len(o.inputs))]
+	out := o.outputs[o.reg.rand.Intn(len(o.outputs))]
 
-	// Add gene at end of innovation order
-	o.oinnov = append(o.oinnov, g)
+	p := nodePair{in, out}
+	o.addGene(newGene(o.reg.connect(p), p, defaultWeight))
+}
 
-	// The rest of the function deals with insering the node at an approriate
-	// place in the evalutaion order
+// hasNode reports whether the organism has a node with the given ID
+func (o *organism) hasNode(id nodeID) bool {
+	_, ok := o.nodes[id]
+	return ok
+}
 
-	// If ´g´ is the first gene then just insert it and where're done.
-	if len(o.oinnov) == 1 {
-		o.oeval = append(o.oeval, g)
+func (o *organism) addBias(id nodeID) {
+	// Disallow adding bias to terminal nodes
+	if o.terminalNodes[id] {
 		return
 	}
 
-	// Find the last gene that ´g´ accepts input from
-	inputDep := -1
-	// Find the first gene that 'g' outputs to
-	outputDep := -1
-
-	// Store the index of the last gene that share the same input node (if any)
-	// so that we can inser our gene immediately after it if there's no output
-	// dependency.
-	lastCommonInput := -1
-	_ = lastCommonInput
+	// Check that the node isn't already biased
+	if o.biasGene(id) != nil {
+		return
+	}
 
-	lastCommonOutput := -1
-	_ = lastCommonOutput
+	p := nodePair{biasID, id}
+	g := newGene(o.reg.connect(p), p, o.newBias())
+	o.obias = append(o.obias, g)
+}
 
-	for i, x := range o.oeval {
-		if x.p.output == g.p.input {
-			inputDep = i
+// biasGene returns the bias gene of the node with the given ID, or nil if the
+// node isn't biased
+func (o *organism) biasGene(id nodeID) *gene {
+	for _, g := range o.obias {
+		if g.p.output == id {
+			return g
 		}
+	}
 
-		if outputDep == -1 && x.p.input == g.p.output {
-			outputDep = i
-		}
+	return nil
+}
 
-		if x.p.input == g.p.input {
-			lastCommonInput = i
-		}
+func (o *organism) addNode(id nodeID) {
+	o.nodes[id] = 0
 
-		if x.p.output == g.p.output {
-			lastCommonOutput = i
-		}
+	if _, ok := o.acts[id]; !ok && !o.terminalNodes[id] {
+		o.acts[id] = o.conf.activation
+		o.aggs[id] = o.conf.aggregation
 	}
 
-	//fmt.Printf("inputDep: %-4d outputDep: %-4d\n", inputDep, outputDep)
+	o.addBias(id)
+}
 
-	if inputDep == -1 && outputDep == -1 {
-		o.oeval = append([]*gene{g}, o.oeval...)
-		return
+// addGene adds a copy of ´g´ to the organism, updates the evaluation order
+// and reports whether the gene was added. A gene closing a cycle is rejected
+// unless recurrence is configured.
+func (o *organism) addGene(g *gene) bool {
+	if !o.conf.Recurrent && o.closesCycle(g.p) {
+		return false
 	}
 
-	if inputDep != -1 && outputDep != -1 {
-		if !o.conf.Recurrent {
-			// If recurrency is not permitted then the output dependencies must
-			// occur before the input dependencies.
-			if outputDep <= inputDep {
-				//fmt.Println("InputDep ", inputDep, " OutputDep ", outputDep)
-				//fmt.Println("Add: ", g)
-				//fmt.Println(o)
-				panic("recurrence not configured")
-			}
-		}
-	}
+	o.insertGene(g)
+	o.sortEvaluation()
 
-	if inputDep != -1 {
-		// Insert the new gene immediately after its last dependency
-		o.oeval = append(o.oeval[:inputDep+1], append([]*gene{g}, o.oeval[inputDep+1:]...)...)
-		return
-	}
+	return true
+}
 
-	if outputDep != -1 {
-		// Insert the new gene immediately before its first depedant
-		o.oeval = append(o.oeval[:outputDep], append([]*gene{g}, o.oeval[outputDep:]...)...)
-		return
+// insertGene adds a copy of ´g´ at its position in the innovation order
+// without updating the evaluation order, call sortEvaluation once done
+// inserting. The registry hands out known innovation numbers again for
+// connections made before, so the gene doesn't necessarily go last. Unlike
+// addGene it doesn't check for cycles.
+func (o *organism) insertGene(g *gene) {
+	if _, ok := o.nodes[g.p.input]; !ok {
+		panic(fmt.Sprintf("node not found %d", g.p.input))
 	}
 
-	if lastCommonInput != -1 {
-		// 'g' doesn't depend on any other gene, append at the end of the
-		// evaluation order.
-		o.oeval = append(o.oeval[:lastCommonInput+1], append([]*gene{g}, o.oeval[lastCommonInput+1:]...)...)
-		return
+	if _, ok := o.nodes[g.p.output]; !ok {
+		panic(fmt.Sprintf("node not found %d", g.p.output))
 	}
 
-	// 'g' doesn't depend on any other gene, append at the end of the
-	// evaluation order.
-	//o.oeval = append(o.oeval, g)
-	//o.oeval = append([]*gene{g}, o.oeval...)
+	i := sort.Search(len(o.oinnov), func(i int) bool {
+		return o.oinnov[i].innov > g.innov
+	})
 
+	o.oinnov = append(o.oinnov, nil)
+	copy(o.oinnov[i+1:], o.oinnov[i:])
+	o.oinnov[i] = g.copy()
 }
 
 func withConnectStrategy(s connectStrategy) organismOpt {
@@ -316,6 +388,14 @@ func withConnectStrategy(s connectStrategy) organismOpt {
 	}
 }
 
+// withHiddenLayer gives the organism a hidden layer of the nodes ´ids´ when
+// it's connected with the full strategy
+func withHiddenLayer(ids []nodeID) organismOpt {
+	return func(o *organism) {
+		o.hidden = ids
+	}
+}
+
 func (o *organism) Eval(input []float64) []float64 {
 	if len(input) != len(o.inputs) {
 		panic("Length of input vector must equal number of input nodes")
@@ -325,84 +405,136 @@ func (o *organism) Eval(input []float64) []float64 {
 		o.nodes[id] = input[i]
 	}
 
-	// Initialize each node with the corresponding weighted bias value
-	for _, g := range o.obias {
-		o.nodes[g.p.output] = g.activate(biasOutput) * g.weight
+	if o.ins == nil {
+		o.ins = make(map[nodeID][]float64, len(o.acts))
 	}
 
-	// Clear the output nodes
-	for _, id := range o.outputs {
+	// Clear the hidden and output nodes
+	for id := range o.acts {
 		o.nodes[id] = 0
+		o.ins[id] = o.ins[id][:0]
+	}
+
+	// Initialize each node with the corresponding weighted bias value
+	for _, g := range o.obias {
+		o.nodes[g.p.output] += biasOutput * g.weight
 	}
 
-	// Iterate over the gene evaluation order and update the nodes accordingly
+	// Iterate over the gene evaluation order and collect the weighted inputs
+	// of each node
 	for _, g := range o.oeval {
 		if g.disabled {
 			// Skip disabled genes
 			continue
 		}
 
-		input := o.nodes[g.p.input]
-
-		v := g.activate(input) * g.weight
-
-		o.nodes[g.p.output] += v
+		o.ins[g.p.output] = append(o.ins[g.p.output], o.value(g.p.input)*g.weight)
 	}
 
 	// Copy the output nodes to the output slice
 	output := make([]float64, len(o.outputs))
 	for i, id := range o.outputs {
-		output[i] = o.nodes[id]
+		output[i] = o.value(id)
 	}
 
 	return output
 }
 
-// Mutation things
-
-func (o *organism) getRecurrentNodePair() nodePair {
-	firstIdx := 1 + randIntn(len(o.oeval)-1)
-	lastIdx := randIntn(firstIdx)
+// value returns the output of a node, that is its bias plus the aggregate of
+// its weighted inputs passed through its activation function. A node without
+// inputs aggregates to 0. Input nodes output their input unchanged.
+func (o *organism) value(id nodeID) float64 {
+	a, ok := o.acts[id]
+	if !ok {
+		return o.nodes[id]
+	}
 
-	input := o.oeval[firstIdx].p.input
-	output := o.oeval[lastIdx].p.output
+	x := o.nodes[id]
+	if ins := o.ins[id]; len(ins) > 0 {
+		x += o.aggs[id].f(ins)
+	}
 
-	return nodePair{input, output}
+	return a.f(x)
 }
 
-func (o *organism) getNodePair() nodePair {
-	if o.conf.Recurrent && randFloat64() < o.conf.RecurrentConnProb {
-		return o.getRecurrentNodePair()
+// evaluate calculates the fitness of the organism. A panic raised by the
+// Trainer or the FitnessCalculator is returned as an error.
+func (o *organism) evaluate(tf TrainerFactory, cf FitnessCalculatorFactory) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("evaluation of organism %d failed: %v", o.id, r)
+		}
+	}()
+
+	n := compile(o)
+	t := tf.New()
+	c := cf.New()
+
+	if o.conf.Recurrent {
+		o.evaluateSequence(t, c, newRecurrentNetwork(n, o.conf.SettleIterations))
+	} else if bt, ok := t.(BatchTrainer); ok {
+		inputs := bt.Batch()
+		outputs := make([][]float64, len(inputs))
+		buf := make([]float64, len(inputs)*n.Outputs())
+		for i := range outputs {
+			outputs[i] = buf[i*n.Outputs() : (i+1)*n.Outputs()]
+		}
+
+		n.EvalBatch(inputs, outputs)
+		for i, input := range inputs {
+			c.AddResult(input, outputs[i])
+		}
+	} else {
+		for input, ok := t.Next(); ok; input, ok = t.Next() {
+			// The FitnessCalculator may hold on to the output
+			output := make([]float64, n.Outputs())
+			copy(output, n.Eval(input))
+			c.AddResult(input, output)
+		}
 	}
 
-	// Randomly select two genes, ´first´ and ´last´, from the gene evaluation
-	// order such that ´first´ occurs before ´last´. Choose the input node from
-	// ´first´ and the output node from ´last´. This ensures that the
-	// connection is not recurrent.
+	o.fitness = c.CalculateFitness()
+	if math.IsNaN(o.fitness) {
+		return fmt.Errorf("evaluation of organism %d failed: fitness is NaN", o.id)
+	}
 
-	// If length is N then firstIdx should be in the range [0, (N - 1))
-	n := len(o.oeval)
-	firstIdx := randIntn(n - 1)
-	// The last index should be in the range (firstIdx, N-1]
-	lastIdx := firstIdx + 1 + randIntn(n-(firstIdx+1))
+	return nil
+}
 
-	input := o.oeval[firstIdx].p.input
-	output := o.oeval[lastIdx].p.output
+// evaluateSequence feeds the inputs of ´t´ to the recurrent network ´r´ in
+// order, the state of the network carries over from one input to the next
+func (o *organism) evaluateSequence(t Trainer, c FitnessCalculator, r *RecurrentNetwork) {
+	step := func(input []float64) {
+		output := make([]float64, r.Outputs())
+		copy(output, r.Step(input))
+		c.AddResult(input, output)
+	}
 
-	return nodePair{input, output}
+	if bt, ok := t.(BatchTrainer); ok {
+		for _, input := range bt.Batch() {
+			step(input)
+		}
+		return
+	}
+
+	for input, ok := t.Next(); ok; input, ok = t.Next() {
+		step(input)
+	}
 }
 
+// Mutation things
+
 func (o *organism) mutateWeight() {
 	for _, g := range o.oinnov {
 		if g.disabled {
 			continue
 		}
 
-		if randFloat64() > o.conf.WeightMutationProb {
+		if o.reg.rand.Float64() > o.conf.WeightMutationProb {
 			continue
 		}
 
-		w := rand.NormFloat64() * o.conf.WeightMutationStandardDeviation
+		w := o.reg.rand.NormFloat64() * o.conf.WeightMutationStandardDeviation
 		// Clamp the weight modification so that it doesn't exceed the weight
 		// mutation power
 		if w < -o.conf.WeightMutationPower {
@@ -415,31 +547,70 @@ func (o *organism) mutateWeight() {
 	}
 }
 
-func (o *organism) mutateConnectNodes(connCache map[nodePair]*gene) {
-	p := o.getNodePair()
+// mutateConnectNodes connects two unconnected nodes. The connection is
+// recurrent with probability RecurrentConnProb when recurrence is configured
+// and never otherwise. Node pairs that don't fit are rejected, after
+// connectAttempts rejections the mutation gives up until next time.
+func (o *organism) mutateConnectNodes() {
+	recurrent := o.conf.Recurrent && o.reg.rand.Float64() < o.conf.RecurrentConnProb
 
-	for _, g := range o.oinnov {
-		if g.p == p {
-			// These nodes are already connected, try again next time
-			return
+	// Inputs only feed other nodes and outputs only feed other nodes
+	// through recurrent connections
+	inputs := make(map[nodeID]bool, len(o.inputs))
+	for _, id := range o.inputs {
+		inputs[id] = true
+	}
+
+	succ := o.successors()
+	sources := make([]nodeID, 0, len(o.nodes))
+	targets := make([]nodeID, 0, len(o.nodes))
+	for _, id := range o.nodeOrder() {
+		switch {
+		case inputs[id]:
+			sources = append(sources, id)
+		case o.terminalNodes[id]:
+			if recurrent {
+				sources = append(sources, id)
+			}
+			targets = append(targets, id)
+		default:
+			sources = append(sources, id)
+			targets = append(targets, id)
 		}
 	}
 
-	if g, ok := connCache[p]; ok {
-		// This innovation has already been made somewhere else
-		o.addGene(g)
+	for i := 0; i < connectAttempts; i++ {
+		p := nodePair{
+			input:  sources[o.reg.rand.Intn(len(sources))],
+			output: targets[o.reg.rand.Intn(len(targets))],
+		}
+
+		if o.connected(p) || cyclic(succ, p) != recurrent {
+			continue
+		}
+
+		fanIn := 1
+		for _, g := range o.oinnov {
+			if g.p.output == p.output && !g.disabled {
+				fanIn++
+			}
+		}
+
+		o.insertGene(newGene(o.reg.connect(p), p, o.newWeight(fanIn)))
+		o.sortEvaluation()
 		return
 	}
-
-	// This is a new innovation
-	g := newGene(p, defaultWeight, o.conf.activate)
-	connCache[p] = g
-	o.addGene(g)
 }
 
-func (o *organism) mutateAddNode(nodeCache map[nodePair]genePair) {
+func (o *organism) mutateAddNode() {
+	if len(o.oinnov) == 0 {
+		// Sparse initial connectivity leaves nothing to split, wait for a
+		// connection to be added
+		return
+	}
+
 	// When adding a new node don't consider genes involving the bias node
-	i := randIntn(len(o.oinnov))
+	i := o.reg.rand.Intn(len(o.oinnov))
 	g := o.oinnov[i]
 
 	if g.disabled {
@@ -447,39 +618,191 @@ func (o *organism) mutateAddNode(nodeCache map[nodePair]genePair) {
 		return
 	}
 
-	p, ok := nodeCache[g.p]
-	if ok {
-		// This innovation has already been made somewhere else
-		o.nodes[p.alpha.p.output] = 0
-		o.addGene(p.alpha)
-		o.addGene(p.beta)
-		g.disabled = true
-
-		o.addBias(p.alpha.p.output)
+	id, alphaInnov, betaInnov := o.reg.split(g.p)
+	if _, ok := o.nodes[id]; ok {
+		// The connection has already been split in this organism, try again
+		// next time
 		return
 	}
 
-	id := nodeIDGenerator()
 	o.addNode(id)
 
-	alpha := newGene(nodePair{g.p.input, id}, defaultWeight, o.conf.activate)
-	beta := newGene(nodePair{id, g.p.output}, g.weight, o.conf.activate)
-	nodeCache[g.p] = genePair{alpha, beta}
-	o.addGene(alpha)
-	o.addGene(beta)
+	alpha := newGene(alphaInnov, nodePair{g.p.input, id}, defaultWeight)
+	beta := newGene(betaInnov, nodePair{id, g.p.output}, g.weight)
+	// The new node has no other connection, splitting can't close a cycle
+	o.insertGene(alpha)
+	o.insertGene(beta)
+	o.sortEvaluation()
 	g.disabled = true
 }
 
-func (o *organism) mutate(connCache map[nodePair]*gene, nodeCache map[nodePair]genePair) {
+// mutateDeleteConnection removes a gene picked at random, enabled or not. The
+// nodes it connected are kept, a hidden node left without inputs outputs its
+// activated bias.
+func (o *organism) mutateDeleteConnection() {
+	if len(o.oinnov) == 0 {
+		return
+	}
+
+	g := o.oinnov[o.reg.rand.Intn(len(o.oinnov))]
+	o.removeGenes(func(x *gene) bool {
+		return x == g
+	})
+}
+
+// mutateDeleteNode removes a hidden node picked at random along with its bias
+// and every gene connecting it
+func (o *organism) mutateDeleteNode() {
+	hidden := make([]nodeID, 0, len(o.acts))
+	for _, id := range o.functionNodes() {
+		if !o.terminalNodes[id] {
+			hidden = append(hidden, id)
+		}
+	}
+
+	if len(hidden) == 0 {
+		return
+	}
+
+	id := hidden[o.reg.rand.Intn(len(hidden))]
+
+	delete(o.nodes, id)
+	delete(o.acts, id)
+	delete(o.aggs, id)
+	delete(o.ins, id)
+
+	obias := o.obias[:0]
+	for _, g := range o.obias {
+		if g.p.output != id {
+			obias = append(obias, g)
+		}
+	}
+	o.obias = obias
+
+	o.removeGenes(func(g *gene) bool {
+		return g.p.input == id || g.p.output == id
+	})
+}
+
+// removeGenes removes the genes for which ´remove´ returns true and updates
+// the evaluation order
+func (o *organism) removeGenes(remove func(*gene) bool) {
+	oinnov := o.oinnov[:0]
+	for _, g := range o.oinnov {
+		if !remove(g) {
+			oinnov = append(oinnov, g)
+		}
+	}
+
+	// Don't hold on to the removed genes
+	for i := len(oinnov); i < len(o.oinnov); i++ {
+		o.oinnov[i] = nil
+	}
+
+	o.oinnov = oinnov
+	o.sortEvaluation()
+}
+
+// mutateActivation replaces the activation function of hidden and output
+// nodes with one picked at random from the ActivationOptions
+func (o *organism) mutateActivation() {
+	if o.conf.ActivationMutationProb <= 0 {
+		return
+	}
+
+	options := o.conf.activationOptions
+	for _, id := range o.functionNodes() {
+		if o.reg.rand.Float64() >= o.conf.ActivationMutationProb {
+			continue
+		}
+
+		o.acts[id] = options[o.reg.rand.Intn(len(options))]
+	}
+}
+
+// mutateAggregation replaces the aggregation function of hidden and output
+// nodes with one picked at random from the AggregationOptions
+func (o *organism) mutateAggregation() {
+	if o.conf.AggregationMutationProb <= 0 {
+		return
+	}
+
+	options := o.conf.aggregationOptions
+	for _, id := range o.functionNodes() {
+		if o.reg.rand.Float64() >= o.conf.AggregationMutationProb {
+			continue
+		}
+
+		o.aggs[id] = options[o.reg.rand.Intn(len(options))]
+	}
+}
+
+// functionNodes returns the IDs of the hidden and output nodes in ascending
+// order, so that mutations visiting them are reproducible
+func (o *organism) functionNodes() []nodeID {
+	ids := make([]nodeID, 0, len(o.acts))
+	for id := range o.acts {
+		ids = append(ids, id)
+	}
+
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i] < ids[j]
+	})
+
+	return ids
+}
+
+// mutate mutates the organism. While ´simplify´ is set nodes and connections
+// are only ever deleted. With PhasedPruning they are only ever deleted while
+// ´simplify´ is set.
+func (o *organism) mutate(simplify bool) {
 	o.mutateWeight()
 
-	if randFloat64() < o.conf.ConnectNodesMutationProb {
-		o.mutateConnectNodes(connCache)
+	o.mutateActivation()
+
+	o.mutateAggregation()
+
+	if !simplify {
+		if o.reg.rand.Float64() < o.conf.ConnectNodesMutationProb {
+			o.mutateConnectNodes()
+		}
+
+		if o.reg.rand.Float64() < o.conf.AddNodeMutationProb {
+			o.mutateAddNode()
+		}
+	}
+
+	if o.conf.PhasedPruning && !simplify {
+		return
+	}
+
+	// Only draw when the deletions are configured so that the other
+	// mutations are drawn as before
+	if p := o.conf.DeleteConnectionMutationProb; p > 0 && o.reg.rand.Float64() < p {
+		o.mutateDeleteConnection()
+	}
+
+	if p := o.conf.DeleteNodeMutationProb; p > 0 && o.reg.rand.Float64() < p {
+		o.mutateDeleteNode()
 	}
+}
+
+// complexity returns the number of hidden nodes and genes, disabled genes
+// included
+func (o *organism) complexity() int {
+	return len(o.acts) - len(o.outputs) + len(o.oinnov)
+}
 
-	if randFloat64() < o.conf.AddNodeMutationProb {
-		o.mutateAddNode(nodeCache)
+// enabledGenes returns the number of enabled genes
+func (o *organism) enabledGenes() int {
+	n := 0
+	for _, g := range o.oinnov {
+		if !g.disabled {
+			n++
+		}
 	}
+
+	return n
 }
 
 func (o *organism) isDisjoint() bool {
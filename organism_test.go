@@ -1,21 +1,20 @@
 package neater
 
 import (
-	"sync/atomic"
+	"math"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 )
 
-func createInputsOuputs(c *Configuration) ([]nodeID, []nodeID) {
-	resetNodeID()
+func createInputsOuputs(reg *registry, c *Configuration) ([]nodeID, []nodeID) {
 	inputs := make([]nodeID, c.Inputs)
 	for i := range inputs {
-		inputs[i] = nextNodeID()
+		inputs[i] = reg.nextNodeID()
 	}
 	outputs := make([]nodeID, c.Outputs)
 	for i := range outputs {
-		outputs[i] = nextNodeID()
+		outputs[i] = reg.nextNodeID()
 	}
 
 	return inputs, outputs
@@ -32,75 +31,82 @@ func TestEval(t *testing.T) {
 		{
 			name: "single unit",
 			conf: &Configuration{
-				Inputs:   1,
-				Outputs:  1,
-				activate: unit,
+				Inputs:      1,
+				Outputs:     1,
+				activation:  activation{ActivateUnit, unit},
+				aggregation: aggregation{AggregateSum, sum},
 			},
 
 			input:  []float64{1},
-			expect: []float64{defaultWeight * unit(1)},
+			expect: []float64{unit(defaultWeight * 1)},
 		},
 		{
 			name: "single sigmoid",
 			conf: &Configuration{
-				Inputs:   1,
-				Outputs:  1,
-				activate: sigmoid,
+				Inputs:      1,
+				Outputs:     1,
+				activation:  activation{ActivateSigmoid, sigmoid},
+				aggregation: aggregation{AggregateSum, sum},
 			},
 
 			input:  []float64{1},
-			expect: []float64{defaultWeight * sigmoid(1)},
+			expect: []float64{sigmoid(defaultWeight * 1)},
 		},
 		{
 			name: "double unit",
 			conf: &Configuration{
-				Inputs:   2,
-				Outputs:  2,
-				activate: unit,
+				Inputs:      2,
+				Outputs:     2,
+				activation:  activation{ActivateUnit, unit},
+				aggregation: aggregation{AggregateSum, sum},
 			},
 
 			input:  []float64{1, 2},
-			expect: []float64{defaultWeight * unit(1), defaultWeight * unit(2)},
+			expect: []float64{unit(defaultWeight * 1), unit(defaultWeight * 2)},
 		},
 		{
 			name: "double sigmoid",
 			conf: &Configuration{
-				Inputs:   2,
-				Outputs:  2,
-				activate: sigmoid,
+				Inputs:      2,
+				Outputs:     2,
+				activation:  activation{ActivateSigmoid, sigmoid},
+				aggregation: aggregation{AggregateSum, sum},
 			},
 
 			input:  []float64{1, 2},
-			expect: []float64{defaultWeight * sigmoid(1), defaultWeight * sigmoid(2)},
+			expect: []float64{sigmoid(defaultWeight * 1), sigmoid(defaultWeight * 2)},
 		},
 		{
 			name: "single split",
 			conf: &Configuration{
-				Inputs:   1,
-				Outputs:  2,
-				activate: sigmoid,
+				Inputs:      1,
+				Outputs:     2,
+				activation:  activation{ActivateSigmoid, sigmoid},
+				aggregation: aggregation{AggregateSum, sum},
 			},
 
 			input:  []float64{1},
-			expect: []float64{defaultWeight * sigmoid(1), defaultWeight * sigmoid(1)},
+			expect: []float64{sigmoid(defaultWeight * 1), sigmoid(defaultWeight * 1)},
 		},
 		{
 			name: "single join",
 			conf: &Configuration{
-				Inputs:   2,
-				Outputs:  1,
-				activate: sigmoid,
+				Inputs:      2,
+				Outputs:     1,
+				activation:  activation{ActivateSigmoid, sigmoid},
+				aggregation: aggregation{AggregateSum, sum},
 			},
 
 			input:  []float64{1, 2},
-			expect: []float64{defaultWeight*sigmoid(1) + defaultWeight*sigmoid(2)},
+			expect: []float64{sigmoid(defaultWeight*1 + defaultWeight*2)},
 		},
 	}
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			inputs, outputs := createInputsOuputs(test.conf)
-			o := newOrganism(test.conf, inputs, outputs,
+			reg := newRegistry(test.conf)
+			inputs, outputs := createInputsOuputs(reg, test.conf)
+			o := newOrganism(test.conf, reg, inputs, outputs,
 				withConnectStrategy(connectFlow))
 
 			output := o.Eval(test.input)
@@ -110,12 +116,6 @@ func TestEval(t *testing.T) {
 }
 
 func TestAddNotRecurrent(t *testing.T) {
-	var nCount uint64
-	nodeIDGenerator = func() nodeID {
-		nCount++
-		return nodeID(nCount)
-	}
-
 	tests := []struct {
 		name   string
 		conf   *Configuration
@@ -133,9 +133,10 @@ func TestAddNotRecurrent(t *testing.T) {
 
 			name: "One additional node connecting input and output",
 			conf: &Configuration{
-				Inputs:   1,
-				Outputs:  1,
-				activate: unit,
+				Inputs:      1,
+				Outputs:     1,
+				activation:  activation{ActivateUnit, unit},
+				aggregation: aggregation{AggregateSum, sum},
 			},
 			pairs: []nodePair{
 				nodePair{1, 2},
@@ -154,9 +155,10 @@ func TestAddNotRecurrent(t *testing.T) {
 
 			name: "Two inputs join and then split to two outputs",
 			conf: &Configuration{
-				Inputs:   2,
-				Outputs:  2,
-				activate: unit,
+				Inputs:      2,
+				Outputs:     2,
+				activation:  activation{ActivateUnit, unit},
+				aggregation: aggregation{AggregateSum, sum},
 			},
 			pairs: []nodePair{
 				nodePair{1, 5},
@@ -178,9 +180,10 @@ func TestAddNotRecurrent(t *testing.T) {
 
 			name: "Complex topology 1",
 			conf: &Configuration{
-				Inputs:   3,
-				Outputs:  3,
-				activate: unit,
+				Inputs:      3,
+				Outputs:     3,
+				activation:  activation{ActivateUnit, unit},
+				aggregation: aggregation{AggregateSum, sum},
 			},
 			pairs: []nodePair{
 				nodePair{1, 7},
@@ -198,19 +201,17 @@ func TestAddNotRecurrent(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			inputs, outputs := createInputsOuputs(test.conf)
+			reg := newRegistry(test.conf)
+			inputs, outputs := createInputsOuputs(reg, test.conf)
 
-			o := newOrganism(test.conf, inputs, outputs,
+			o := newOrganism(test.conf, reg, inputs, outputs,
 				withConnectStrategy(connectNone))
 
-			// Reset node ID counter
-			nCount = 0
 			for _, p := range test.pairs {
+				o.addNode(p.input)
+				o.addNode(p.output)
 
-				o.nodes[p.input] = 0
-				o.nodes[p.output] = 0
-
-				g := newGene(p, defaultWeight, unit)
+				g := newGene(reg.nextInnov(), p, defaultWeight)
 				o.addGene(g)
 			}
 
@@ -221,12 +222,6 @@ func TestAddNotRecurrent(t *testing.T) {
 }
 
 func TestPanicCases(t *testing.T) {
-	var nCount uint64
-	nodeIDGenerator = func() nodeID {
-		nCount++
-		return nodeID(nCount)
-	}
-
 	tests := []struct {
 		name  string
 		conf  *Configuration
@@ -236,8 +231,10 @@ func TestPanicCases(t *testing.T) {
 		{
 			name: "case 1",
 			conf: &Configuration{
-				Inputs:  2,
-				Outputs: 1,
+				Inputs:      2,
+				Outputs:     1,
+				activation:  activation{ActivateSigmoid, sigmoid},
+				aggregation: aggregation{AggregateSum, sum},
 			},
 			pairs: []nodePair{
 				nodePair{1, 3},
@@ -250,8 +247,10 @@ func TestPanicCases(t *testing.T) {
 		{
 			name: "case 2",
 			conf: &Configuration{
-				Inputs:  2,
-				Outputs: 1,
+				Inputs:      2,
+				Outputs:     1,
+				activation:  activation{ActivateSigmoid, sigmoid},
+				aggregation: aggregation{AggregateSum, sum},
 			},
 			pairs: []nodePair{
 				nodePair{1, 3},    // 1
@@ -270,8 +269,10 @@ func TestPanicCases(t *testing.T) {
 		{
 			name: "case 3",
 			conf: &Configuration{
-				Inputs:  2,
-				Outputs: 1,
+				Inputs:      2,
+				Outputs:     1,
+				activation:  activation{ActivateSigmoid, sigmoid},
+				aggregation: aggregation{AggregateSum, sum},
 			},
 			pairs: []nodePair{
 				nodePair{1, 3},    // 1
@@ -291,19 +292,17 @@ func TestPanicCases(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			inputs, outputs := createInputsOuputs(test.conf)
-			o := newOrganism(test.conf, inputs, outputs,
+			reg := newRegistry(test.conf)
+			inputs, outputs := createInputsOuputs(reg, test.conf)
+			o := newOrganism(test.conf, reg, inputs, outputs,
 				withConnectStrategy(connectNone))
 
-			// Reset node ID counter
-			nCount = 0
 			for _, p := range test.pairs {
-
-				o.nodes[p.input] = 0
-				o.nodes[p.output] = 0
+				o.addNode(p.input)
+				o.addNode(p.output)
 
 				//fmt.Printf("Add: %s\n", p)
-				g := newGene(p, defaultWeight, unit)
+				g := newGene(reg.nextInnov(), p, defaultWeight)
 				o.addGene(g)
 				//fmt.Printf("---Iteration %d----\n%s\n\n", i+1, o)
 			}
@@ -314,100 +313,99 @@ func TestPanicCases(t *testing.T) {
 }
 
 func TestMutateAddNode(t *testing.T) {
-	newGene := func(p nodePair, w float64, f activationFunction, innov geneID, disabled bool) *gene {
+	newGene := func(p nodePair, w float64, innov geneID, disabled bool) *gene {
 		return &gene{
 			innov:    innov,
 			p:        p,
 			weight:   w,
 			disabled: disabled,
-			activate: f,
 		}
 	}
 
 	tests := []struct {
-		name      string
-		conf      *Configuration
-		randVal   int
-		nCount    uint64
-		gCount    uint64
-		nodeCache map[nodePair]genePair
-		genes     []*gene
-		expect    []*gene
+		name    string
+		conf    *Configuration
+		randVal int
+		nCount  uint64
+		gCount  uint64
+		splits  map[nodePair]*splitInnovation
+		genes   []*gene
+		expect  []*gene
 	}{
 		{
-			name: "One inuput one output no cache",
+			name: "One inuput one output no history",
 			conf: &Configuration{
 				Inputs:            1,
 				Outputs:           1,
 				InitialBiasWeight: 0,
-				activate:          sigmoid,
+				activation:        activation{ActivateSigmoid, sigmoid},
+				aggregation:       aggregation{AggregateSum, sum},
 			},
-			randVal:   0,
-			nCount:    2,
-			gCount:    1,
-			nodeCache: make(map[nodePair]genePair),
+			randVal: 0,
+			nCount:  2,
+			gCount:  1,
+			splits:  make(map[nodePair]*splitInnovation),
 			genes: []*gene{
-				newGene(nodePair{1, 2}, 1, sigmoid, geneID(1), false),
+				newGene(nodePair{1, 2}, 1, geneID(1), false),
 			},
 			expect: []*gene{
-				newGene(nodePair{1, 2}, 1, sigmoid, geneID(1), true),
-				// Skip geneID 2 which would be assigned when connecting bias
-				newGene(nodePair{1, 3}, 1, sigmoid, geneID(3), false),
-				newGene(nodePair{3, 2}, 1, sigmoid, geneID(4), false),
+				newGene(nodePair{1, 2}, 1, geneID(1), true),
+				newGene(nodePair{1, 3}, 1, geneID(2), false),
+				newGene(nodePair{3, 2}, 1, geneID(3), false),
 			},
 		},
 		{
-			name: "One inuput one output with cache",
+			name: "One inuput one output with history",
 			conf: &Configuration{
-				Inputs:   1,
-				Outputs:  1,
-				activate: sigmoid,
+				Inputs:      1,
+				Outputs:     1,
+				activation:  activation{ActivateSigmoid, sigmoid},
+				aggregation: aggregation{AggregateSum, sum},
 			},
 			randVal: 0,
 			nCount:  2,
 			gCount:  1,
-			nodeCache: map[nodePair]genePair{
-				nodePair{1, 2}: genePair{
-					// Skip geneID 2 which would be assigned when connecting bias
-					newGene(nodePair{1, 3}, 1, sigmoid, geneID(3), false),
-					newGene(nodePair{3, 2}, 1, sigmoid, geneID(4), false),
+			splits: map[nodePair]*splitInnovation{
+				nodePair{1, 2}: &splitInnovation{
+					node:  7,
+					alpha: geneID(8),
+					beta:  geneID(9),
 				},
 			},
 			genes: []*gene{
-				newGene(nodePair{1, 2}, 1, sigmoid, geneID(1), false),
+				newGene(nodePair{1, 2}, 1, geneID(1), false),
 			},
 			expect: []*gene{
-				newGene(nodePair{1, 2}, 1, sigmoid, geneID(1), true),
-				// Skip geneID 2 which would be assigned when connecting bias
-				newGene(nodePair{1, 3}, 1, sigmoid, geneID(3), false),
-				newGene(nodePair{3, 2}, 1, sigmoid, geneID(4), false),
+				newGene(nodePair{1, 2}, 1, geneID(1), true),
+				newGene(nodePair{1, 7}, 1, geneID(8), false),
+				newGene(nodePair{7, 2}, 1, geneID(9), false),
 			},
 		},
 		{
-			name: "Two inputs two outputs no cache",
+			name: "Two inputs two outputs no history",
 			conf: &Configuration{
-				Inputs:   2,
-				Outputs:  2,
-				activate: sigmoid,
+				Inputs:      2,
+				Outputs:     2,
+				activation:  activation{ActivateSigmoid, sigmoid},
+				aggregation: aggregation{AggregateSum, sum},
 			},
-			randVal:   0,
-			nCount:    4,
-			gCount:    4,
-			nodeCache: make(map[nodePair]genePair),
+			randVal: 0,
+			nCount:  4,
+			gCount:  4,
+			splits:  make(map[nodePair]*splitInnovation),
 			genes: []*gene{
-				newGene(nodePair{1, 3}, 1, sigmoid, geneID(1), false),
-				newGene(nodePair{1, 4}, 1, sigmoid, geneID(2), false),
-				newGene(nodePair{2, 3}, 1, sigmoid, geneID(3), false),
-				newGene(nodePair{2, 4}, 1, sigmoid, geneID(4), false),
+				newGene(nodePair{1, 3}, 1, geneID(1), false),
+				newGene(nodePair{1, 4}, 1, geneID(2), false),
+				newGene(nodePair{2, 3}, 1, geneID(3), false),
+				newGene(nodePair{2, 4}, 1, geneID(4), false),
 			},
 			expect: []*gene{
-				newGene(nodePair{1, 3}, 1, sigmoid, geneID(1), true),
-				newGene(nodePair{1, 4}, 1, sigmoid, geneID(2), false),
-				newGene(nodePair{2, 3}, 1, sigmoid, geneID(3), false),
-				newGene(nodePair{2, 4}, 1, sigmoid, geneID(4), false),
-				// Skip geneID 5 which would be assigned when connecting bias
-				newGene(nodePair{1, 5}, 1, sigmoid, geneID(6), false),
-				newGene(nodePair{5, 3}, 1, sigmoid, geneID(7), false),
+				newGene(nodePair{1, 3}, 1, geneID(1), true),
+				newGene(nodePair{1, 4}, 1, geneID(2), false),
+				newGene(nodePair{2, 3}, 1, geneID(3), false),
+				newGene(nodePair{2, 4}, 1, geneID(4), false),
+				newGene(nodePair{1, 5}, 1, geneID(5), false),
+				newGene(nodePair{5, 3}, 1, geneID(6), false),
 			},
 		},
 	}
@@ -415,12 +413,15 @@ func TestMutateAddNode(t *testing.T) {
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 
+			reg := newRegistry(test.conf)
+			reg.splits = test.splits
+
 			// Reset node ID counter
-			atomic.StoreUint64(&nodeIDCount, test.nCount)
+			reg.nodes.restore(test.nCount)
 			// Reset gene ID counter
-			atomic.StoreUint64(&innovCount, test.gCount)
+			reg.innovs.restore(test.gCount)
 
-			o := newCleanOrganism(test.conf)
+			o := newCleanOrganism(test.conf, reg)
 
 			for _, g := range test.genes {
 				o.nodes[g.p.input] = 0
@@ -428,11 +429,9 @@ func TestMutateAddNode(t *testing.T) {
 				o.addGene(g)
 			}
 
-			randIntn = func(int) int {
-				return test.randVal
-			}
+			reg.rand = &stubRandom{ints: []int{test.randVal}}
 
-			o.mutateAddNode(test.nodeCache)
+			o.mutateAddNode()
 
 			require.Equal(t, len(test.expect), len(o.oinnov))
 			for i, x := range test.expect {
@@ -442,3 +441,396 @@ func TestMutateAddNode(t *testing.T) {
 		})
 	}
 }
+
+func TestEvalNodeActivation(t *testing.T) {
+	conf := &Configuration{
+		Inputs:      1,
+		Outputs:     1,
+		activation:  activation{ActivateUnit, unit},
+		aggregation: aggregation{AggregateSum, sum},
+	}
+
+	reg := newRegistry(conf)
+	inputs, outputs := createInputsOuputs(reg, conf)
+	o := newOrganism(conf, reg, inputs, outputs)
+
+	// Split the connection and make the new node a sine unit
+	reg.rand = &stubRandom{ints: []int{0}}
+	o.mutateAddNode()
+
+	hidden := o.oinnov[1].p.output
+	o.acts[hidden] = activation{ActivateSin, math.Sin}
+	o.acts[outputs[0]] = activation{ActivateAbs, math.Abs}
+
+	// The activation is applied once to the sum of a node's inputs
+	o.obias[0].weight = 0.5
+	require.Equal(t, []float64{math.Abs(math.Sin(-2 + 0.5))}, o.Eval([]float64{-2}))
+}
+
+func TestMutateActivation(t *testing.T) {
+	conf := &Configuration{
+		Inputs:                 1,
+		Outputs:                2,
+		ActivationMutationProb: 0.5,
+		activation:             activation{ActivateSigmoid, sigmoid},
+		aggregation:            aggregation{AggregateSum, sum},
+		activationOptions: []activation{
+			{ActivateSigmoid, sigmoid},
+			{ActivateTanh, math.Tanh},
+			{ActivateSin, math.Sin},
+		},
+	}
+
+	reg := newRegistry(conf)
+	inputs, outputs := createInputsOuputs(reg, conf)
+	o := newOrganism(conf, reg, inputs, outputs)
+
+	// The first output is left alone, the second is given the third option
+	reg.rand = &stubRandom{floats: []float64{0.7, 0.2}, ints: []int{2}}
+	o.mutateActivation()
+
+	require.Equal(t, ActivateSigmoid, o.acts[outputs[0]].name)
+	require.Equal(t, ActivateSin, o.acts[outputs[1]].name)
+
+	// Input nodes have no activation function
+	_, ok := o.acts[inputs[0]]
+	require.False(t, ok)
+}
+
+func TestEvalNodeAggregation(t *testing.T) {
+	tests := []struct {
+		aggregation aggregation
+		expect      float64
+	}{
+		{aggregation: aggregation{AggregateSum, sum}, expect: 6},
+		{aggregation: aggregation{AggregateProduct, product}, expect: -12},
+		{aggregation: aggregation{AggregateMax, maxValue}, expect: 4},
+		{aggregation: aggregation{AggregateMaxAbs, maxAbs}, expect: 4},
+		{aggregation: aggregation{AggregateMedian, median}, expect: 3},
+	}
+
+	for _, test := range tests {
+		t.Run(test.aggregation.name, func(t *testing.T) {
+			conf := &Configuration{
+				Inputs:      3,
+				Outputs:     1,
+				activation:  activation{ActivateUnit, unit},
+				aggregation: test.aggregation,
+			}
+
+			reg := newRegistry(conf)
+			inputs, outputs := createInputsOuputs(reg, conf)
+			o := newOrganism(conf, reg, inputs, outputs)
+
+			// The same organism is evaluated twice to make sure that the
+			// inputs of the previous evaluation are discarded
+			require.Equal(t, []float64{test.expect}, o.Eval([]float64{3, -1, 4}))
+			require.Equal(t, []float64{test.expect}, o.Eval([]float64{3, -1, 4}))
+		})
+	}
+}
+
+func TestMutateAggregation(t *testing.T) {
+	conf := &Configuration{
+		Inputs:                  1,
+		Outputs:                 2,
+		AggregationMutationProb: 0.5,
+		activation:              activation{ActivateSigmoid, sigmoid},
+		aggregation:             aggregation{AggregateSum, sum},
+		aggregationOptions: []aggregation{
+			{AggregateSum, sum},
+			{AggregateProduct, product},
+			{AggregateMax, maxValue},
+		},
+	}
+
+	reg := newRegistry(conf)
+	inputs, outputs := createInputsOuputs(reg, conf)
+	o := newOrganism(conf, reg, inputs, outputs)
+
+	// The first output is given the second option, the second is left alone
+	reg.rand = &stubRandom{floats: []float64{0.1, 0.9}, ints: []int{1}}
+	o.mutateAggregation()
+
+	require.Equal(t, AggregateProduct, o.aggs[outputs[0]].name)
+	require.Equal(t, AggregateSum, o.aggs[outputs[1]].name)
+
+	// Input nodes have no aggregation function
+	_, ok := o.aggs[inputs[0]]
+	require.False(t, ok)
+}
+
+func TestConnectStrategies(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy connectStrategy
+		hidden   []nodeID
+		rand     *stubRandom
+		expect   []nodePair
+	}{
+		{
+			name:     "full",
+			strategy: connectFull,
+			expect:   []nodePair{{1, 4}, {1, 5}, {2, 4}, {2, 5}, {3, 4}, {3, 5}},
+		},
+		{
+			name:     "flow",
+			strategy: connectFlow,
+			expect:   []nodePair{{1, 4}, {2, 5}, {3, 4}},
+		},
+		{
+			name:     "none",
+			strategy: connectNone,
+			expect:   []nodePair{},
+		},
+		{
+			name:     "random",
+			strategy: connectRandom,
+			rand:     &stubRandom{floats: []float64{0.1, 0.9, 0.6, 0.3, 0.5, 0.2}},
+			expect:   []nodePair{{1, 4}, {2, 5}, {3, 5}},
+		},
+		{
+			name:     "fs-neat",
+			strategy: connectFSNEAT,
+			rand:     &stubRandom{ints: []int{2, 0}},
+			expect:   []nodePair{{3, 4}},
+		},
+		{
+			name:     "full with hidden layer",
+			strategy: connectFull,
+			hidden:   []nodeID{6, 7},
+			expect: []nodePair{
+				{1, 6}, {1, 7}, {2, 6}, {2, 7}, {3, 6}, {3, 7},
+				{6, 4}, {6, 5}, {7, 4}, {7, 5},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			conf := &Configuration{
+				Inputs:                3,
+				Outputs:               2,
+				InitialConnectionProb: 0.5,
+				activation:            activation{ActivateSigmoid, sigmoid},
+				aggregation:           aggregation{AggregateSum, sum},
+			}
+
+			reg := newRegistry(conf)
+			inputs, outputs := createInputsOuputs(reg, conf)
+			if test.rand != nil {
+				reg.rand = test.rand
+			}
+
+			o := newOrganism(conf, reg, inputs, outputs,
+				withConnectStrategy(test.strategy), withHiddenLayer(test.hidden))
+
+			pairs := make([]nodePair, len(o.oinnov))
+			for i, g := range o.oinnov {
+				pairs[i] = g.p
+			}
+			require.Equal(t, test.expect, pairs)
+
+			// Hidden nodes are biased and given the default functions
+			require.Len(t, o.obias, len(test.hidden))
+			require.Len(t, o.acts, len(outputs)+len(test.hidden))
+
+			// Organisms without connections can still be mutated
+			reg.rand = &stubRandom{ints: []int{0}}
+			o.mutateAddNode()
+		})
+	}
+}
+
+func TestNewNeatHiddenLayer(t *testing.T) {
+	conf := xorConfiguration(1)
+	conf.InitialHiddenNodes = 3
+
+	n, err := NewNeat(conf)
+	require.NoError(t, err)
+
+	// Every initial organism shares the hidden nodes and their innovations
+	a := newGenome(n.species[0].population[0])
+	require.Len(t, a.Nodes(), 6)
+	require.Len(t, a.Genes(), 9)
+
+	for _, o := range n.species[0].population[1:] {
+		b := newGenome(o)
+		require.Equal(t, a.Nodes(), b.Nodes())
+		for i, g := range a.Genes() {
+			require.Equal(t, g.Innovation, b.Genes()[i].Innovation)
+		}
+	}
+}
+
+// requireConsistent checks that the genes, nodes and functions of ´o´ agree
+// with each other and that ´o´ still compiles
+func requireConsistent(t *testing.T, o *organism) {
+	require.ElementsMatch(t, o.oinnov, o.oeval)
+
+	for _, g := range o.oinnov {
+		require.True(t, o.hasNode(g.p.input), "gene %s", g.p)
+		require.True(t, o.hasNode(g.p.output), "gene %s", g.p)
+	}
+
+	for _, g := range o.obias {
+		require.Contains(t, o.acts, g.p.output)
+	}
+
+	for id := range o.acts {
+		require.True(t, o.hasNode(id))
+		require.Contains(t, o.aggs, id)
+	}
+
+	for id := range o.terminalNodes {
+		require.True(t, o.hasNode(id))
+	}
+
+	require.Len(t, compile(o).Eval([]float64{1, 1}), len(o.outputs))
+}
+
+func TestMutateDeleteConnection(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy connectStrategy
+		ints     []int
+		expect   []nodePair
+	}{
+		{
+			name:     "input to hidden",
+			strategy: connectFull,
+			ints:     []int{0},
+			expect:   []nodePair{{1, 5}, {2, 4}, {2, 5}, {4, 3}, {5, 3}},
+		},
+		{
+			name:     "hidden to output",
+			strategy: connectFull,
+			ints:     []int{4},
+			expect:   []nodePair{{1, 4}, {1, 5}, {2, 4}, {2, 5}, {5, 3}},
+		},
+		{
+			name:     "no genes",
+			strategy: connectNone,
+			expect:   []nodePair{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			conf := xorConfiguration(1)
+			require.NoError(t, conf.resolve())
+
+			reg := newRegistry(conf)
+			o := newOrganism(conf, reg, []nodeID{1, 2}, []nodeID{3},
+				withConnectStrategy(test.strategy), withHiddenLayer([]nodeID{4, 5}))
+
+			reg.rand = &stubRandom{ints: test.ints}
+			o.mutateDeleteConnection()
+
+			pairs := make([]nodePair, 0, len(o.oinnov))
+			for _, g := range o.oinnov {
+				pairs = append(pairs, g.p)
+			}
+
+			require.Equal(t, test.expect, pairs)
+			requireConsistent(t, o)
+		})
+	}
+}
+
+func TestMutateDeleteNode(t *testing.T) {
+	conf := xorConfiguration(1)
+	require.NoError(t, conf.resolve())
+
+	reg := newRegistry(conf)
+	o := newOrganism(conf, reg, []nodeID{1, 2}, []nodeID{3}, withHiddenLayer([]nodeID{4, 5}))
+	require.Len(t, o.obias, 2)
+
+	tests := []struct {
+		name   string
+		ints   []int
+		nodes  []nodeID
+		expect []nodePair
+	}{
+		{
+			name:   "first hidden node",
+			ints:   []int{0},
+			nodes:  []nodeID{1, 2, 3, 5},
+			expect: []nodePair{{1, 5}, {2, 5}, {5, 3}},
+		},
+		{
+			name:   "last hidden node",
+			ints:   []int{0},
+			nodes:  []nodeID{1, 2, 3},
+			expect: []nodePair{},
+		},
+		{
+			// Terminal nodes are never deleted
+			name:   "no hidden node",
+			nodes:  []nodeID{1, 2, 3},
+			expect: []nodePair{},
+		},
+	}
+
+	// The cases apply one after the other to the same organism
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reg.rand = &stubRandom{ints: test.ints}
+			o.mutateDeleteNode()
+
+			nodes := make([]nodeID, 0, len(o.nodes))
+			for id := range o.nodes {
+				nodes = append(nodes, id)
+			}
+
+			pairs := make([]nodePair, 0, len(o.oinnov))
+			for _, g := range o.oinnov {
+				pairs = append(pairs, g.p)
+			}
+
+			require.ElementsMatch(t, test.nodes, nodes)
+			require.Equal(t, test.expect, pairs)
+			require.Len(t, o.obias, len(nodes)-3)
+			requireConsistent(t, o)
+		})
+	}
+
+	// The organism can grow again
+	reg.nodes.restore(5)
+	o.addGene(newGene(reg.connect(nodePair{1, 3}), nodePair{1, 3}, 1))
+	reg.rand = &stubRandom{ints: []int{0}}
+	o.mutateAddNode()
+	requireConsistent(t, o)
+	require.Equal(t, 4, o.complexity())
+}
+
+func TestMutateSimplify(t *testing.T) {
+	tests := []struct {
+		name     string
+		phased   bool
+		simplify bool
+		expect   int
+	}{
+		{name: "deletes", phased: false, simplify: false, expect: 5},
+		{name: "complexifying phase", phased: true, simplify: false, expect: 6},
+		{name: "simplifying phase", phased: true, simplify: true, expect: 5},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			conf := xorConfiguration(1)
+			conf.AddNodeMutationProb = 0
+			conf.ConnectNodesMutationProb = 0
+			conf.DeleteConnectionMutationProb = 1
+			conf.PhasedPruning = test.phased
+			require.NoError(t, conf.resolve())
+
+			o := newOrganism(conf, newRegistry(conf), []nodeID{1, 2}, []nodeID{3},
+				withHiddenLayer([]nodeID{4, 5}))
+			o.mutate(test.simplify)
+
+			require.Len(t, o.oinnov, test.expect)
+			requireConsistent(t, o)
+		})
+	}
+}
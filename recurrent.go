@@ -0,0 +1,79 @@
+package neater
+
+type (
+	// RecurrentNetwork is a neural network compiled from a Genome that keeps
+	// the output of its nodes between calls, for sequences and other tasks
+	// where the past matters. Every node is updated synchronously from the
+	// outputs of the previous update, so the order of the connections
+	// doesn't matter and a signal travels one connection per update. A
+	// RecurrentNetwork is not safe for concurrent use.
+	RecurrentNetwork struct {
+		n *Network
+
+		// settle is the number of updates per step
+		settle int
+
+		// cur holds the node outputs of the last update, next receives the
+		// outputs of the update in progress
+		cur  []float64
+		next []float64
+	}
+)
+
+// newRecurrentNetwork builds a RecurrentNetwork from the compiled network
+// ´n´, which it takes ownership of. Each step performs ´settle´ updates.
+func newRecurrentNetwork(n *Network, settle int) *RecurrentNetwork {
+	if settle < 1 {
+		panic("A recurrent network needs at least one update per step")
+	}
+
+	return &RecurrentNetwork{
+		n:      n,
+		settle: settle,
+		cur:    make([]float64, len(n.values)),
+		next:   make([]float64, len(n.values)),
+	}
+}
+
+// Inputs returns the number of inputs of the network
+func (r *RecurrentNetwork) Inputs() int {
+	return r.n.Inputs()
+}
+
+// Outputs returns the number of outputs of the network
+func (r *RecurrentNetwork) Outputs() int {
+	return r.n.Outputs()
+}
+
+// Step feeds ´input´ to the network, updates every node as many times as the
+// network settles per step and returns the output. The returned slice is
+// reused by the next call to Step.
+func (r *RecurrentNetwork) Step(input []float64) []float64 {
+	if len(input) != r.n.inputs {
+		panic("Length of input vector must equal number of input nodes")
+	}
+
+	for i := 0; i < r.settle; i++ {
+		copy(r.cur, input)
+
+		for j := range r.n.nodes {
+			x := &r.n.nodes[j]
+			r.next[x.slot] = r.n.activate(x, r.cur)
+		}
+
+		r.cur, r.next = r.next, r.cur
+	}
+
+	for i, slot := range r.n.outputs {
+		r.n.out[i] = r.cur[slot]
+	}
+
+	return r.n.out
+}
+
+// Reset clears the state of the network, as if it had never been stepped
+func (r *RecurrentNetwork) Reset() {
+	for i := range r.cur {
+		r.cur[i] = 0
+	}
+}
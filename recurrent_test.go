@@ -0,0 +1,145 @@
+package neater
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// selfLoopOrganism returns an organism with one input and one output that
+// feeds back into itself
+func selfLoopOrganism() *organism {
+	conf := &Configuration{
+		Inputs:           1,
+		Outputs:          1,
+		Recurrent:        true,
+		SettleIterations: 1,
+		activation:       activation{ActivateUnit, unit},
+		aggregation:      aggregation{AggregateSum, sum},
+	}
+
+	reg := newRegistry(conf)
+	inputs, outputs := createInputsOuputs(reg, conf)
+	o := newOrganism(conf, reg, inputs, outputs)
+
+	p := nodePair{outputs[0], outputs[0]}
+	o.addGene(newGene(reg.connect(p), p, defaultWeight))
+
+	return o
+}
+
+func TestRecurrentNetworkStep(t *testing.T) {
+	r := newRecurrentNetwork(compile(selfLoopOrganism()), 1)
+	require.Equal(t, 1, r.Inputs())
+	require.Equal(t, 1, r.Outputs())
+
+	// The output accumulates the inputs
+	require.Equal(t, []float64{1}, r.Step([]float64{1}))
+	require.Equal(t, []float64{3}, r.Step([]float64{2}))
+	require.Equal(t, []float64{3}, r.Step([]float64{0}))
+
+	r.Reset()
+	require.Equal(t, []float64{1}, r.Step([]float64{1}))
+
+	allocs := testing.AllocsPerRun(10, func() {
+		r.Step([]float64{0})
+	})
+	require.Zero(t, allocs)
+}
+
+func TestRecurrentNetworkSettle(t *testing.T) {
+	conf := &Configuration{
+		Inputs:      1,
+		Outputs:     1,
+		activation:  activation{ActivateUnit, unit},
+		aggregation: aggregation{AggregateSum, sum},
+	}
+
+	reg := newRegistry(conf)
+	inputs, outputs := createInputsOuputs(reg, conf)
+	o := newOrganism(conf, reg, inputs, outputs)
+
+	// Split the connection so that the signal takes two updates to reach
+	// the output
+	reg.rand = &stubRandom{ints: []int{0}}
+	o.mutateAddNode()
+
+	r := newRecurrentNetwork(compile(o), 1)
+	require.Equal(t, []float64{0}, r.Step([]float64{1}))
+	require.Equal(t, []float64{1}, r.Step([]float64{1}))
+
+	r = newRecurrentNetwork(compile(o), 2)
+	require.Equal(t, []float64{1}, r.Step([]float64{1}))
+}
+
+func TestRecurrentNetworkFeedForward(t *testing.T) {
+	g := evolvedGenome(t)
+	n := g.Network()
+
+	// Given enough updates a feed-forward genome settles on the output of
+	// the Network
+	r := g.RecurrentNetwork(len(g.o.nodes))
+	for _, input := range [][]float64{{0, 0}, {0, 1}, {1, 0}, {1, 1}} {
+		r.Reset()
+		require.Equal(t, n.Eval(input), r.Step(input))
+	}
+}
+
+// sequenceTrainer hands out a fixed sequence of inputs
+type sequenceTrainer struct {
+	inputs [][]float64
+}
+
+func (t *sequenceTrainer) Next() ([]float64, bool) {
+	if len(t.inputs) == 0 {
+		return nil, false
+	}
+
+	input := t.inputs[0]
+	t.inputs = t.inputs[1:]
+
+	return input, true
+}
+
+func (t *sequenceTrainer) Reset() {}
+
+// recordingCalculator keeps the outputs it's given
+type recordingCalculator struct {
+	outputs [][]float64
+}
+
+func (c *recordingCalculator) AddResult(input, output []float64) {
+	c.outputs = append(c.outputs, output)
+}
+
+func (c *recordingCalculator) CalculateFitness() float64 {
+	return float64(len(c.outputs))
+}
+
+func (c *recordingCalculator) Reset() {
+	c.outputs = nil
+}
+
+func TestEvaluateSequence(t *testing.T) {
+	o := selfLoopOrganism()
+
+	var c *recordingCalculator
+	tf := TrainerFactory{
+		New: func() Trainer {
+			return &sequenceTrainer{inputs: [][]float64{{1}, {2}, {0}}}
+		},
+	}
+	cf := FitnessCalculatorFactory{
+		New: func() FitnessCalculator {
+			c = new(recordingCalculator)
+			return c
+		},
+	}
+
+	// Every evaluation starts from a clean state, which carries over from
+	// one input to the next
+	for i := 0; i < 2; i++ {
+		require.NoError(t, o.evaluate(tf, cf))
+		require.Equal(t, [][]float64{{1}, {3}, {3}}, c.outputs)
+	}
+}
@@ -0,0 +1,199 @@
+package neater
+
+import (
+	"math/rand"
+	"sync/atomic"
+)
+
+type (
+	// allocator hands out unique IDs in increasing order
+	allocator struct {
+		last uint64
+	}
+
+	// connInnovation records the innovation number given to a new connection
+	connInnovation struct {
+		innov geneID
+		seen  int
+	}
+
+	// splitInnovation records the node and innovation numbers given when a
+	// connection was split by a new node
+	splitInnovation struct {
+		node  nodeID
+		alpha geneID
+		beta  geneID
+		seen  int
+	}
+
+	// registry hands out the IDs used by a Neat instance and keeps track of
+	// the structural innovations made by its population, so that the same
+	// connection or node split is given the same innovation numbers and node
+	// ID regardless of which species or generation it appears in. It also
+	// holds the random source behind every stochastic decision of the
+	// instance.
+	registry struct {
+		conf       *Configuration
+		generation int
+
+		rand random
+		// source is the seeded source behind rand, nil when the source is
+		// supplied by the user
+		source *countingSource
+
+		nodes     allocator
+		innovs    allocator
+		organisms allocator
+		species   allocator
+
+		conns  map[nodePair]*connInnovation
+		splits map[nodePair]*splitInnovation
+	}
+
+	// countingSource is a seeded random source that counts the numbers drawn
+	// from it, so that its state can be restored by replaying the draws
+	countingSource struct {
+		src   rand.Source64
+		seed  int64
+		draws uint64
+	}
+)
+
+// next returns the next unused ID
+func (a *allocator) next() uint64 {
+	return atomic.AddUint64(&a.last, 1)
+}
+
+// current returns the last ID handed out
+func (a *allocator) current() uint64 {
+	return atomic.LoadUint64(&a.last)
+}
+
+// restore sets the last ID handed out so that allocation can resume where it
+// left off
+func (a *allocator) restore(last uint64) {
+	atomic.StoreUint64(&a.last, last)
+}
+
+func newCountingSource(seed int64) *countingSource {
+	return &countingSource{
+		src:  rand.NewSource(seed).(rand.Source64),
+		seed: seed,
+	}
+}
+
+func (s *countingSource) Int63() int64 {
+	s.draws++
+	return s.src.Int63()
+}
+
+func (s *countingSource) Uint64() uint64 {
+	s.draws++
+	return s.src.Uint64()
+}
+
+func (s *countingSource) Seed(seed int64) {
+	s.src.Seed(seed)
+	s.seed = seed
+	s.draws = 0
+}
+
+// skip draws ´n´ numbers from the source
+func (s *countingSource) skip(n uint64) {
+	for ; n > 0; n-- {
+		s.Int63()
+	}
+}
+
+func newRegistry(c *Configuration) *registry {
+	r := &registry{
+		conf:   c,
+		conns:  make(map[nodePair]*connInnovation),
+		splits: make(map[nodePair]*splitInnovation),
+	}
+
+	switch {
+	case c.Source != nil:
+		r.rand = rand.New(c.Source)
+	default:
+		r.source = newCountingSource(c.Seed)
+		r.rand = rand.New(r.source)
+	}
+
+	return r
+}
+
+func (r *registry) nextNodeID() nodeID {
+	return nodeID(r.nodes.next())
+}
+
+func (r *registry) nextInnov() geneID {
+	return geneID(r.innovs.next())
+}
+
+func (r *registry) nextOrganismID() organismID {
+	return organismID(r.organisms.next())
+}
+
+func (r *registry) nextSpeciesID() speciesID {
+	return speciesID(r.species.next())
+}
+
+// connect returns the innovation number of a connection between the nodes of
+// ´p´
+func (r *registry) connect(p nodePair) geneID {
+	if c, ok := r.conns[p]; ok {
+		c.seen = r.generation
+		return c.innov
+	}
+
+	c := &connInnovation{
+		innov: r.nextInnov(),
+		seen:  r.generation,
+	}
+	r.conns[p] = c
+
+	return c.innov
+}
+
+// split returns the node ID and the innovation numbers of the two connections
+// that replace the connection between the nodes of ´p´ when it is split
+func (r *registry) split(p nodePair) (nodeID, geneID, geneID) {
+	if s, ok := r.splits[p]; ok {
+		s.seen = r.generation
+		return s.node, s.alpha, s.beta
+	}
+
+	id := r.nextNodeID()
+	s := &splitInnovation{
+		node:  id,
+		alpha: r.connect(nodePair{p.input, id}),
+		beta:  r.connect(nodePair{id, p.output}),
+		seen:  r.generation,
+	}
+	r.splits[p] = s
+
+	return s.node, s.alpha, s.beta
+}
+
+// advance moves the registry to the next generation and forgets innovations
+// that haven't been seen for InnovationHistory generations
+func (r *registry) advance() {
+	r.generation++
+
+	if r.conf.InnovationHistory <= 0 {
+		return
+	}
+
+	for p, c := range r.conns {
+		if r.generation-c.seen >= r.conf.InnovationHistory {
+			delete(r.conns, p)
+		}
+	}
+
+	for p, s := range r.splits {
+		if r.generation-s.seen >= r.conf.InnovationHistory {
+			delete(r.splits, p)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package neater
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistryConnect(t *testing.T) {
+	reg := newRegistry(&Configuration{})
+
+	a := reg.connect(nodePair{1, 2})
+	b := reg.connect(nodePair{1, 3})
+
+	require.NotEqual(t, a, b)
+	require.Equal(t, a, reg.connect(nodePair{1, 2}))
+	require.Equal(t, b, reg.connect(nodePair{1, 3}))
+}
+
+func TestRegistrySplit(t *testing.T) {
+	reg := newRegistry(&Configuration{})
+
+	id, alpha, beta := reg.split(nodePair{1, 2})
+	require.Equal(t, alpha, reg.connect(nodePair{1, id}))
+	require.Equal(t, beta, reg.connect(nodePair{id, 2}))
+
+	x, y, z := reg.split(nodePair{1, 2})
+	require.Equal(t, id, x)
+	require.Equal(t, alpha, y)
+	require.Equal(t, beta, z)
+}
+
+func TestRegistryHistory(t *testing.T) {
+	tests := []struct {
+		name        string
+		history     int
+		generations int
+		remembered  bool
+	}{
+		{
+			name:        "remember forever",
+			history:     0,
+			generations: 100,
+			remembered:  true,
+		},
+		{
+			name:        "within window",
+			history:     3,
+			generations: 2,
+			remembered:  true,
+		},
+		{
+			name:        "outside window",
+			history:     3,
+			generations: 3,
+			remembered:  false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reg := newRegistry(&Configuration{InnovationHistory: test.history})
+
+			innov := reg.connect(nodePair{1, 2})
+			id, _, _ := reg.split(nodePair{1, 2})
+
+			for i := 0; i < test.generations; i++ {
+				reg.advance()
+			}
+
+			x, _, _ := reg.split(nodePair{1, 2})
+			require.Equal(t, test.remembered, innov == reg.connect(nodePair{1, 2}))
+			require.Equal(t, test.remembered, id == x)
+		})
+	}
+}
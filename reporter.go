@@ -0,0 +1,120 @@
+package neater
+
+import (
+	"fmt"
+	"io"
+	"log"
+)
+
+type (
+	// Reporter is notified about the progress of the training. The callbacks
+	// are made from the goroutine calling Train.
+	Reporter interface {
+		// GenerationStart is called before a generation is trained
+		GenerationStart(iteration int)
+
+		// GenerationEnd is called when a generation has been trained
+		GenerationEnd(s *Stats)
+
+		// SpeciesCreated is called when a new species is created
+		SpeciesCreated(id uint64)
+
+		// SpeciesExtinct is called when a species is removed
+		SpeciesExtinct(id uint64)
+
+		// NewChampion is called when an organism with a higher fitness than
+		// any before it is found
+		NewChampion(s *Stats)
+
+		// EvaluationError is called when the evaluation of an organism fails
+		EvaluationError(err error)
+	}
+
+	// QuietReporter ignores all notifications, embed it to implement only
+	// a subset of the Reporter interface
+	QuietReporter struct{}
+
+	// ConsoleReporter clears the terminal and prints a summary after every
+	// generation
+	ConsoleReporter struct {
+		QuietReporter
+		w io.Writer
+	}
+
+	// LogReporter logs a single line per generation as well as notable events
+	LogReporter struct {
+		l *log.Logger
+	}
+)
+
+func (QuietReporter) GenerationStart(iteration int) {}
+
+func (QuietReporter) GenerationEnd(s *Stats) {}
+
+func (QuietReporter) SpeciesCreated(id uint64) {}
+
+func (QuietReporter) SpeciesExtinct(id uint64) {}
+
+func (QuietReporter) NewChampion(s *Stats) {}
+
+func (QuietReporter) EvaluationError(err error) {}
+
+func NewConsoleReporter(w io.Writer) *ConsoleReporter {
+	return &ConsoleReporter{w: w}
+}
+
+func (r *ConsoleReporter) GenerationEnd(s *Stats) {
+	fmt.Fprint(r.w, "\033[2J")
+	fmt.Fprintf(r.w, "---General--------\n")
+	fmt.Fprintf(r.w, "Iterations:      %10d\n", s.Iterations)
+	fmt.Fprintf(r.w, "NbrSpecies:      %10d\n", s.NbrSpecies)
+	fmt.Fprintf(r.w, "Evaluations:     %10d\n", s.Evaluations)
+	fmt.Fprintf(r.w, "Extinct:         %10d\n", s.Extinct)
+	fmt.Fprintf(r.w, "Total extinct:   %10d\n", s.TotalExtinct)
+
+	fmt.Fprintf(r.w, "---Fitness--------\n")
+	fmt.Fprintf(r.w, "Mean:            %10f\n", s.Fitness.Mean)
+	fmt.Fprintf(r.w, "Median:          %10f\n", s.Fitness.Median)
+	fmt.Fprintf(r.w, "StdDev:          %10f\n", s.Fitness.StdDev)
+
+	if len(s.Species) > 0 {
+		fmt.Fprintf(r.w, "---Top Species----\n")
+		fmt.Fprintf(r.w, "ID:              %10d\n", s.Species[0].ID)
+		fmt.Fprintf(r.w, "Generation:      %10d\n", s.Species[0].Age)
+		fmt.Fprintf(r.w, "Population size: %10d\n", s.Species[0].Size)
+	}
+
+	fmt.Fprintf(r.w, "---Top Organism---\n")
+	fmt.Fprintf(r.w, "ID:              %10d\n", s.Best.ID)
+	fmt.Fprintf(r.w, "Fitness:         %10f\n", s.Best.Fitness)
+	fmt.Fprintf(r.w, "Node count:      %10d\n", s.Best.Nodes)
+	fmt.Fprintf(r.w, "Gene count:      %10d\n", s.Best.Genes)
+	fmt.Fprintf(r.w, "\n\n")
+}
+
+func NewLogReporter(l *log.Logger) *LogReporter {
+	return &LogReporter{l: l}
+}
+
+func (r *LogReporter) GenerationStart(iteration int) {}
+
+func (r *LogReporter) GenerationEnd(s *Stats) {
+	r.l.Printf("iteration=%d species=%d evaluations=%d extinct=%d mean=%f fitness=%f best=%f",
+		s.Iterations, s.NbrSpecies, s.Evaluations, s.Extinct, s.Fitness.Mean, s.Best.Fitness, s.BestFitness)
+}
+
+func (r *LogReporter) SpeciesCreated(id uint64) {
+	r.l.Printf("species %d created", id)
+}
+
+func (r *LogReporter) SpeciesExtinct(id uint64) {
+	r.l.Printf("species %d extinct", id)
+}
+
+func (r *LogReporter) NewChampion(s *Stats) {
+	r.l.Printf("new champion organism %d fitness=%f", s.Best.ID, s.BestFitness)
+}
+
+func (r *LogReporter) EvaluationError(err error) {
+	r.l.Printf("evaluation error: %v", err)
+}
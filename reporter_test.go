@@ -0,0 +1,101 @@
+package neater
+
+import (
+	"bytes"
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type recordingReporter struct {
+	QuietReporter
+
+	starts    []int
+	ends      []int
+	champions int
+	errs      []error
+}
+
+func (r *recordingReporter) GenerationStart(iteration int) {
+	r.starts = append(r.starts, iteration)
+}
+
+func (r *recordingReporter) GenerationEnd(s *Stats) {
+	r.ends = append(r.ends, s.Iterations)
+}
+
+func (r *recordingReporter) NewChampion(s *Stats) {
+	r.champions++
+}
+
+func (r *recordingReporter) EvaluationError(err error) {
+	r.errs = append(r.errs, err)
+}
+
+type panickingFitnessCalculator struct {
+	xorFitnessCalculator
+}
+
+func (c *panickingFitnessCalculator) CalculateFitness() float64 {
+	panic("boom")
+}
+
+func TestReporters(t *testing.T) {
+	tf, cf := xorFactories()
+
+	n, err := NewNeat(xorConfiguration(1))
+	require.NoError(t, err)
+
+	a := new(recordingReporter)
+	b := new(recordingReporter)
+	n.AddReporter(a)
+	n.AddReporter(b)
+
+	for i := 0; i < 3; i++ {
+		n.Train(tf, cf)
+	}
+
+	for _, r := range []*recordingReporter{a, b} {
+		require.Equal(t, []int{1, 2, 3}, r.starts)
+		require.Equal(t, []int{1, 2, 3}, r.ends)
+		require.True(t, r.champions >= 1)
+		require.Empty(t, r.errs)
+	}
+}
+
+func TestEvaluationError(t *testing.T) {
+	tf, _ := xorFactories()
+	cf := FitnessCalculatorFactory{
+		New: func() FitnessCalculator {
+			return new(panickingFitnessCalculator)
+		},
+	}
+
+	conf := xorConfiguration(1)
+	n, err := NewNeat(conf)
+	require.NoError(t, err)
+
+	r := new(recordingReporter)
+	n.AddReporter(r)
+
+	n.Train(tf, cf)
+
+	require.Len(t, r.errs, conf.InitialPopulationSize)
+	require.Equal(t, float64(0), n.Best().Fitness())
+}
+
+func TestLogReporter(t *testing.T) {
+	tf, cf := xorFactories()
+
+	n, err := NewNeat(xorConfiguration(1))
+	require.NoError(t, err)
+
+	b := new(bytes.Buffer)
+	n.AddReporter(NewLogReporter(log.New(b, "", 0)))
+
+	n.Train(tf, cf)
+
+	require.Contains(t, b.String(), "iteration=1 ")
+	require.Contains(t, b.String(), "new champion")
+}
@@ -0,0 +1,128 @@
+package neater
+
+import (
+	"context"
+	"time"
+)
+
+type (
+	// StopReason describes why a run stopped
+	StopReason string
+
+	// StopCondition is consulted after every generation of a run, the run
+	// stops as soon as a condition returns true
+	StopCondition func(s *Stats) (StopReason, bool)
+
+	// Result describes the outcome of a run
+	Result struct {
+		// Reason is the reason the run stopped
+		Reason StopReason
+
+		// Iterations is the number of generations trained
+		Iterations int
+
+		// Evaluations is the number of fitness evaluations made
+		Evaluations int
+
+		// Elapsed is the time spent training
+		Elapsed time.Duration
+
+		// Fitness is the fitness of the best organism found, Stats.BestFitness
+		Fitness float64
+
+		// Best is the genome of the best organism found since the start,
+		// which isn't necessarily part of the last generation
+		Best *Genome
+	}
+)
+
+const (
+	StopCanceled         = StopReason("canceled")
+	StopFitnessThreshold = StopReason("fitness threshold reached")
+	StopMaxGenerations   = StopReason("maximum number of generations reached")
+	StopMaxEvaluations   = StopReason("maximum number of evaluations reached")
+	StopTimeBudget       = StopReason("time budget exhausted")
+	StopStagnation       = StopReason("population stagnated")
+	StopCheckpointFailed = StopReason("checkpoint failed")
+)
+
+// FitnessThreshold stops the run once the best organism reaches a fitness of
+// at least ´f´
+func FitnessThreshold(f float64) StopCondition {
+	return func(s *Stats) (StopReason, bool) {
+		return StopFitnessThreshold, s.BestFitness >= f
+	}
+}
+
+// MaxGenerations stops the run after ´n´ generations
+func MaxGenerations(n int) StopCondition {
+	return func(s *Stats) (StopReason, bool) {
+		return StopMaxGenerations, s.Iterations >= n
+	}
+}
+
+// MaxEvaluations stops the run after ´n´ fitness evaluations
+func MaxEvaluations(n int) StopCondition {
+	return func(s *Stats) (StopReason, bool) {
+		return StopMaxEvaluations, s.Evaluations >= n
+	}
+}
+
+// TimeBudget stops the run once ´d´ has been spent training
+func TimeBudget(d time.Duration) StopCondition {
+	return func(s *Stats) (StopReason, bool) {
+		return StopTimeBudget, s.Elapsed >= d
+	}
+}
+
+// Stagnation stops the run once the best fitness of the whole population
+// hasn't improved for ´n´ generations
+func Stagnation(n int) StopCondition {
+	return func(s *Stats) (StopReason, bool) {
+		return StopStagnation, s.Iterations-s.Improved >= n
+	}
+}
+
+// Run trains the population until one of the stop conditions is met or the
+// context is canceled. Without any stop conditions Run trains until the
+// context is canceled, in which case the context's error is returned along
+// with the result. When CheckpointInterval is set a checkpoint is written to
+// CheckpointPath every CheckpointInterval generations, the run stops with the
+// error if a checkpoint can't be written.
+func (n *Neat) Run(ctx context.Context, tf TrainerFactory, cf FitnessCalculatorFactory, conds ...StopCondition) (Result, error) {
+	for {
+		select {
+		case <-ctx.Done():
+			return n.result(StopCanceled), ctx.Err()
+		default:
+		}
+
+		n.Train(tf, cf)
+
+		if err := n.checkpoint(); err != nil {
+			return n.result(StopCheckpointFailed), err
+		}
+
+		for _, c := range conds {
+			if reason, stop := c(&n.stats); stop {
+				return n.result(reason), nil
+			}
+		}
+	}
+}
+
+func (n *Neat) result(reason StopReason) Result {
+	r := Result{
+		Reason:      reason,
+		Iterations:  n.stats.Iterations,
+		Evaluations: n.stats.Evaluations,
+		Elapsed:     n.stats.Elapsed,
+		Best:        newGenome(n.fittest),
+	}
+
+	if r.Best != nil {
+		r.Fitness = r.Best.Fitness()
+	}
+
+	return r
+}
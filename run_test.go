@@ -0,0 +1,137 @@
+package neater
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStopConditions(t *testing.T) {
+	tests := []struct {
+		name   string
+		cond   StopCondition
+		stats  Stats
+		reason StopReason
+		expect bool
+	}{
+		{
+			name:   "fitness threshold not reached",
+			cond:   FitnessThreshold(0.9),
+			stats:  Stats{BestFitness: 0.8},
+			reason: StopFitnessThreshold,
+			expect: false,
+		},
+		{
+			name:   "fitness threshold reached",
+			cond:   FitnessThreshold(0.9),
+			stats:  Stats{BestFitness: 0.9},
+			reason: StopFitnessThreshold,
+			expect: true,
+		},
+		{
+			name:   "max generations",
+			cond:   MaxGenerations(10),
+			stats:  Stats{Iterations: 10},
+			reason: StopMaxGenerations,
+			expect: true,
+		},
+		{
+			name:   "max evaluations",
+			cond:   MaxEvaluations(100),
+			stats:  Stats{Evaluations: 99},
+			reason: StopMaxEvaluations,
+			expect: false,
+		},
+		{
+			name:   "time budget",
+			cond:   TimeBudget(time.Second),
+			stats:  Stats{Elapsed: 2 * time.Second},
+			reason: StopTimeBudget,
+			expect: true,
+		},
+		{
+			name:   "stagnation",
+			cond:   Stagnation(5),
+			stats:  Stats{Iterations: 12, Improved: 7},
+			reason: StopStagnation,
+			expect: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reason, stop := test.cond(&test.stats)
+			require.Equal(t, test.expect, stop)
+			require.Equal(t, test.reason, reason)
+		})
+	}
+}
+
+func TestRun(t *testing.T) {
+	tf, cf := xorFactories()
+
+	n, err := NewNeat(xorConfiguration(1))
+	require.NoError(t, err)
+
+	r, err := n.Run(context.Background(), tf, cf, FitnessThreshold(5), MaxGenerations(3))
+	require.NoError(t, err)
+	require.Equal(t, StopMaxGenerations, r.Reason)
+	require.Equal(t, 3, r.Iterations)
+	require.Equal(t, n.Stats().BestFitness, r.Fitness)
+	require.Equal(t, r.Fitness, r.Best.Fitness())
+}
+
+// decliningFitness gives every evaluated organism a lower fitness than the
+// ones evaluated before it
+type decliningFitness struct {
+	evaluations *int
+}
+
+func (decliningFitness) AddResult(input, output []float64) {}
+
+func (f decliningFitness) CalculateFitness() float64 {
+	*f.evaluations++
+	return -float64(*f.evaluations)
+}
+
+func (decliningFitness) Reset() {}
+
+func TestRunBestFound(t *testing.T) {
+	tf, _ := xorFactories()
+
+	evaluations := 0
+	cf := FitnessCalculatorFactory{
+		New: func() FitnessCalculator {
+			return decliningFitness{evaluations: &evaluations}
+		},
+	}
+
+	n, err := NewNeat(xorConfiguration(1))
+	require.NoError(t, err)
+
+	r, err := n.Run(context.Background(), tf, cf, MaxGenerations(3))
+	require.NoError(t, err)
+
+	// The first organism ever evaluated is the best one found, not the
+	// champion of the last generation
+	require.Equal(t, -1.0, r.Fitness)
+	require.Equal(t, -1.0, r.Best.Fitness())
+	require.Less(t, n.Best().Fitness(), r.Fitness)
+}
+
+func TestRunCanceled(t *testing.T) {
+	tf, cf := xorFactories()
+
+	n, err := NewNeat(xorConfiguration(1))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	r, err := n.Run(ctx, tf, cf)
+	require.Equal(t, context.Canceled, err)
+	require.Equal(t, StopCanceled, r.Reason)
+	require.Equal(t, 0, r.Iterations)
+}
@@ -0,0 +1,115 @@
+package neater
+
+import (
+	"math"
+)
+
+type (
+	// Random is the source of randomness handed to a Selector
+	Random interface {
+		// Intn returns a random number in the range [0, n)
+		Intn(n int) int
+
+		// Float64 returns a random number in the range [0.0, 1.0)
+		Float64() float64
+	}
+
+	// Selector picks parents among the surviving organisms of a species
+	Selector interface {
+		// Select returns the index of the selected parent. The fitness slice
+		// is sorted in order of descending fitness and is never empty.
+		Select(fitness []float64, r Random) int
+	}
+
+	// RouletteSelector selects parents with a probability proportional to
+	// their fitness
+	RouletteSelector struct{}
+
+	// RankSelector selects parents with a probability proportional to their
+	// rank, the fittest organism having the highest rank
+	RankSelector struct{}
+
+	// TournamentSelector selects the fittest of Size randomly chosen
+	// organisms
+	TournamentSelector struct {
+		Size int
+	}
+
+	// UniformSelector selects parents with equal probability regardless of
+	// fitness
+	UniformSelector struct{}
+)
+
+const (
+	SelectRoulette   = "roulette"
+	SelectRank       = "rank"
+	SelectTournament = "tournament"
+	SelectUniform    = "uniform"
+
+	defaultTournamentSize = 2
+)
+
+func (RouletteSelector) Select(fitness []float64, r Random) int {
+	// Shift negative fitness so that the least fit organism has a fitness
+	// of 0, non-negative fitness is used as is
+	floor := 0.0
+	for _, f := range fitness {
+		floor = math.Min(floor, f)
+	}
+
+	var total float64
+	for _, f := range fitness {
+		total += f - floor
+	}
+
+	if total == 0 {
+		// No organism has a positive share
+		return r.Intn(len(fitness))
+	}
+
+	x := r.Float64() * total
+	for i, f := range fitness {
+		x -= f - floor
+		if x < 0 {
+			return i
+		}
+	}
+
+	return len(fitness) - 1
+}
+
+func (RankSelector) Select(fitness []float64, r Random) int {
+	n := len(fitness)
+
+	// The organism at index i has rank n - i so the sum of all ranks is
+	// n(n+1)/2
+	x := r.Intn(n * (n + 1) / 2)
+	for i := 0; i < n; i++ {
+		x -= n - i
+		if x < 0 {
+			return i
+		}
+	}
+
+	return n - 1
+}
+
+func (t TournamentSelector) Select(fitness []float64, r Random) int {
+	size := t.Size
+	if size <= 0 {
+		size = defaultTournamentSize
+	}
+
+	best := r.Intn(len(fitness))
+	for i := 1; i < size; i++ {
+		if x := r.Intn(len(fitness)); fitness[x] > fitness[best] {
+			best = x
+		}
+	}
+
+	return best
+}
+
+func (UniformSelector) Select(fitness []float64, r Random) int {
+	return r.Intn(len(fitness))
+}
@@ -0,0 +1,125 @@
+package neater
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type stubRandom struct {
+	ints    []int
+	floats  []float64
+	normals []float64
+}
+
+func (r *stubRandom) Intn(n int) int {
+	x := r.ints[0]
+	r.ints = r.ints[1:]
+	return x % n
+}
+
+func (r *stubRandom) Float64() float64 {
+	x := r.floats[0]
+	r.floats = r.floats[1:]
+	return x
+}
+
+func (r *stubRandom) NormFloat64() float64 {
+	x := r.normals[0]
+	r.normals = r.normals[1:]
+	return x
+}
+
+func TestSelect(t *testing.T) {
+	tests := []struct {
+		name     string
+		selector Selector
+		fitness  []float64
+		random   *stubRandom
+		expect   int
+	}{
+		{
+			name:     "roulette first",
+			selector: RouletteSelector{},
+			fitness:  []float64{4, 2, 1},
+			random:   &stubRandom{floats: []float64{0.5}},
+			expect:   0,
+		},
+		{
+			name:     "roulette second",
+			selector: RouletteSelector{},
+			fitness:  []float64{4, 2, 1},
+			random:   &stubRandom{floats: []float64{0.75}},
+			expect:   1,
+		},
+		{
+			name:     "roulette least fit",
+			selector: RouletteSelector{},
+			fitness:  []float64{4, 2, 1},
+			random:   &stubRandom{floats: []float64{0.9}},
+			expect:   2,
+		},
+		{
+			name:     "roulette near-equal fitness",
+			selector: RouletteSelector{},
+			fitness:  []float64{100, 99},
+			random:   &stubRandom{floats: []float64{0.6}},
+			expect:   1,
+		},
+		{
+			name:     "roulette negative fitness",
+			selector: RouletteSelector{},
+			fitness:  []float64{-1, -2, -3},
+			random:   &stubRandom{floats: []float64{0.7}},
+			expect:   1,
+		},
+		{
+			name:     "roulette equal fitness",
+			selector: RouletteSelector{},
+			fitness:  []float64{1, 1, 1},
+			random:   &stubRandom{floats: []float64{0.9}},
+			expect:   2,
+		},
+		{
+			name:     "roulette zero fitness",
+			selector: RouletteSelector{},
+			fitness:  []float64{0, 0, 0},
+			random:   &stubRandom{ints: []int{2}},
+			expect:   2,
+		},
+		{
+			name:     "rank first",
+			selector: RankSelector{},
+			fitness:  []float64{4, 2, 1},
+			random:   &stubRandom{ints: []int{2}},
+			expect:   0,
+		},
+		{
+			name:     "rank last",
+			selector: RankSelector{},
+			fitness:  []float64{4, 2, 1},
+			random:   &stubRandom{ints: []int{5}},
+			expect:   2,
+		},
+		{
+			name:     "tournament",
+			selector: TournamentSelector{Size: 3},
+			fitness:  []float64{4, 2, 1, 0},
+			random:   &stubRandom{ints: []int{3, 1, 2}},
+			expect:   1,
+		},
+		{
+			name:     "uniform",
+			selector: UniformSelector{},
+			fitness:  []float64{4, 2, 1},
+			random:   &stubRandom{ints: []int{2}},
+			expect:   2,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.expect, test.selector.Select(test.fitness, test.random))
+		})
+	}
+}
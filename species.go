@@ -3,7 +3,6 @@ package neater
 import (
 	"math"
 	"sort"
-	"sync/atomic"
 )
 
 type (
@@ -12,36 +11,39 @@ type (
 	species struct {
 		id         speciesID
 		conf       *Configuration
+		reg        *registry
 		rep        *organism
 		champ      *organism
 		population []*organism
 		generation int
-	}
-)
 
-var (
-	speciesCount = uint64(0)
-)
+		// best is the best raw fitness the species has ever achieved
+		best float64
+		// improved is the generation in which best was last improved
+		improved int
 
-func nextSpeciesID() speciesID {
-	return speciesID(atomic.AddUint64(&speciesCount, 1))
-}
+		// offspring is the number of organisms the species is allotted in the
+		// next generation
+		offspring int
+	}
+)
 
-func newCleanSpecies(c *Configuration) *species {
+func newCleanSpecies(c *Configuration, reg *registry) *species {
 	return &species{
-		id:         nextSpeciesID(),
+		id:         reg.nextSpeciesID(),
 		conf:       c,
-		population: make([]*organism, c.InitialPopulationSize),
+		reg:        reg,
+		population: make([]*organism, 0, c.InitialPopulationSize),
+		best:       math.Inf(-1),
 	}
 }
 
-func newSpecies(c *Configuration, inputs, outputs []nodeID) *species {
-	s := newCleanSpecies(c)
-	o := newOrganism(c, inputs, outputs)
-	s.population[0] = o
-
-	for i := 1; i < len(s.population); i++ {
-		s.population[i] = o.copy()
+func newSpecies(c *Configuration, reg *registry, inputs, outputs []nodeID, opts ...organismOpt) *species {
+	s := newCleanSpecies(c, reg)
+	// Every organism is drawn independently so that random weights and
+	// connections differ between them
+	for i := 0; i < c.InitialPopulationSize; i++ {
+		s.population = append(s.population, newOrganism(c, reg, inputs, outputs, opts...))
 	}
 
 	s.choseRepresentative()
@@ -51,60 +53,75 @@ func newSpecies(c *Configuration, inputs, outputs []nodeID) *species {
 
 func (s *species) choseRepresentative() {
 	// Chose a species representative
-	r := randIntn(len(s.population))
+	r := s.reg.rand.Intn(len(s.population))
 	s.rep = s.population[r]
 }
 
-func (s *species) train(tf TrainerFactory, cf FitnessCalculatorFactory) {
-	for _, o := range s.population {
-		t := tf.New()
-		c := cf.New()
-		for input, ok := t.Next(); ok; input, ok = t.Next() {
-			output := o.Eval(input)
-			c.AddResult(input, output)
-		}
-
-		o.fitness = c.CalculateFitness()
-	}
-
+// rank ranks the population once the fitness of every organism has been
+// calculated
+func (s *species) rank() {
 	// Sort according to fitness
 	sort.Slice(s.population, func(i, j int) bool {
 		return s.population[i].fitness > s.population[j].fitness
 	})
 
-	// Drop the lowest performing organisms
-	threshold := min(s.conf.PopulationThreshold, len(s.population))
-	s.population = s.population[:threshold]
-
 	// Let the fittest organism represent the camp
 	s.champ = s.population[0]
 
-	// Normalize the species fitness
-	s.normalize()
+	// Keep track of when the species last made progress
+	if s.champ.fitness > s.best {
+		s.best = s.champ.fitness
+		s.improved = s.generation
+	}
+}
+
+// truncate drops the lowest performing organisms so that at most
+// PopulationThreshold organisms remain, the population must be ranked
+func (s *species) truncate() {
+	threshold := min(s.conf.PopulationThreshold, len(s.population))
+	s.population = s.population[:threshold]
 
 	// Chose a new species representative
 	s.choseRepresentative()
 }
 
-// normalize normalizes the fitness of the population
-func (s *species) normalize() {
+// normalize calculates the adjusted fitness of the population, the raw
+// fitness is left untouched. The fitness is shifted by ´floor´, the lowest
+// fitness of the whole population, so that the adjusted fitness is never
+// negative.
+func (s *species) normalize(floor float64) {
 	l := float64(len(s.population))
 
 	for _, o := range s.population {
-		o.fitness /= l
+		o.adjusted = (o.fitness - floor) / l
 	}
 }
 
-func (s *species) mutate() []*organism {
-	s.generation++
+// fitness returns the sum of the adjusted fitness of the population
+func (s *species) fitness() float64 {
+	var f float64
+	for _, o := range s.population {
+		f += o.adjusted
+	}
+
+	return f
+}
 
-	// A cache to hold new connection innovations that have already been made
-	// in this generation.
-	connCache := make(map[nodePair]*gene)
+// stagnant reports whether the species has gone DropOffAge generations
+// without improving its best fitness. A DropOffAge of 0 disables stagnation.
+func (s *species) stagnant() bool {
+	if s.conf.DropOffAge <= 0 {
+		return false
+	}
+
+	return s.generation-s.improved >= s.conf.DropOffAge
+}
 
-	// A cache to hold new node innovations that have already been made in this
-	// generation.
-	nodeCache := make(map[nodePair]genePair)
+// mutate mutates every organism but the champion and returns those that no
+// longer belong to the species. While ´simplify´ is set nodes and connections
+// are only ever deleted.
+func (s *species) mutate(simplify bool) []*organism {
+	s.generation++
 
 	// rejectIdx stores the indices of the organisms that are no longer
 	// compatible with the species after mutation
@@ -116,7 +133,7 @@ func (s *species) mutate() []*organism {
 	for _, o := range s.population {
 		// Spare the champ from mutation
 		if o != s.champ {
-			o.mutate(connCache, nodeCache)
+			o.mutate(simplify)
 		}
 	}
 
@@ -219,38 +236,66 @@ func (s *species) distance(a, b *organism) float64 {
 	c3 := s.conf.WeightDifferenceCoefficient
 	e := float64(excessGenes)
 	d := float64(disjointGenes)
-	w := weightDiff / float64(commonGenes)
+
+	// Genomes without genes in common, such as sparsely connected ones,
+	// differ in structure only
+	w := float64(0)
+	if commonGenes > 0 {
+		w = weightDiff / float64(commonGenes)
+	}
 
 	return ((c1*e)+(c2*d))/n + c3*w
 }
 
-// mate mates the top species of the population, the organisms must be sorted
-// in order of ascending fitness before entering this function.
+// mate replaces the population with the species' allotted offspring. The
+// champion survives unchanged and the rest of the offspring are children of
+// the top performers. The organisms must be sorted in order of descending
+// fitness before entering this function.
 func (s *species) mate() {
-	/*
-		// Let only the top performers survive and mate
-		topCutOffIndex := int(float64(s.conf.PopulationThreshold) * s.conf.SurvivalThreshold)
-		s.population = s.population[:min(topCutOffIndex, len(s.population))]
-	*/
-
-	/*
-		cutOffIdx := min(len(s.population), int(math.Sqrt(float64(s.conf.PopulationThreshold+4))))
-		s.population = s.population[:cutOffIdx]
-	*/
-
-	n := len(s.population)
-	children := make([]*organism, 0, n*(n+1)/2)
-
-	for i, a := range s.population {
-		for j, b := range s.population {
-			if i != j {
-				c := s.recombinate(a, b)
-				children = append(children, c)
-			}
+	if s.offspring <= 0 {
+		s.population = s.population[:0]
+		return
+	}
+
+	// Let only the top performers survive and mate
+	survivors := s.population[:s.survivors()]
+
+	fitness := make([]float64, len(survivors))
+	for i, o := range survivors {
+		fitness[i] = o.fitness
+	}
+
+	children := make([]*organism, 0, s.offspring)
+
+	// Let the champion live on
+	children = append(children, s.champ)
+
+	for len(children) < s.offspring {
+		a := survivors[s.conf.selector.Select(fitness, s.reg.rand)]
+		b := survivors[s.conf.selector.Select(fitness, s.reg.rand)]
+
+		if a == b {
+			// No mate available, reproduce asexually
+			children = append(children, a.copy())
+			continue
 		}
+
+		children = append(children, s.recombinate(a, b))
+	}
+
+	s.population = children
+}
+
+// survivors returns the number of top performers allowed to reproduce
+// according to the SurvivalThreshold. At least one organism always survives.
+func (s *species) survivors() int {
+	if s.conf.SurvivalThreshold <= 0 || s.conf.SurvivalThreshold >= 1 {
+		return len(s.population)
 	}
 
-	s.population = append(s.population, children...)
+	n := int(math.Ceil(float64(len(s.population)) * s.conf.SurvivalThreshold))
+
+	return max(1, min(n, len(s.population)))
 }
 
 func (s *species) recombinate(a, b *organism) *organism {
@@ -260,7 +305,7 @@ func (s *species) recombinate(a, b *organism) *organism {
 		a, b = b, a
 	}
 
-	o := newCleanOrganism(a.conf)
+	o := newCleanOrganism(a.conf, a.reg)
 	copy(o.inputs, a.inputs)
 	copy(o.outputs, a.outputs)
 	o.terminalNodes = make(map[nodeID]bool, len(a.terminalNodes))
@@ -268,6 +313,36 @@ func (s *species) recombinate(a, b *organism) *organism {
 		o.terminalNodes[k] = v
 	}
 
+	// Terminal nodes are kept even when no inherited gene connects them, the
+	// inputs can still be connected by mutation and the outputs evaluated
+	for _, id := range o.inputs {
+		o.nodes[id] = 0
+	}
+
+	for _, id := range o.outputs {
+		o.nodes[id] = 0
+	}
+
+	// inherit adds a gene of either parent along with its nodes. The parents
+	// may connect the same nodes in opposite directions, a gene closing a
+	// cycle is left out unless recurrence is configured. A forgotten
+	// innovation may give the parents different genes for the same
+	// connection, only the first one is inherited.
+	succ := make(map[nodeID][]nodeID)
+	pairs := make(map[nodePair]bool, len(a.oinnov))
+	inherit := func(g *gene) {
+		if pairs[g.p] || !o.conf.Recurrent && cyclic(succ, g.p) {
+			return
+		}
+
+		pairs[g.p] = true
+
+		inheritNode(o, g.p.input, a, b)
+		inheritNode(o, g.p.output, a, b)
+		o.insertGene(g)
+		succ[g.p.input] = append(succ[g.p.input], g.p.output)
+	}
+
 	i, j := 0, 0
 
 	// Copy genes and hidden nodes
@@ -289,26 +364,54 @@ func (s *species) recombinate(a, b *organism) *organism {
 			j = min(j+1, len(b.oinnov))
 		}
 
-		o.addNode(g.p.input)
-		o.addNode(g.p.output)
-		o.addGene(g)
+		inherit(g)
 	}
 
 	// Handle trailing genes (if any)
 	for ; i < len(a.oinnov); i++ {
 		g := a.oinnov[i]
-		o.addNode(g.p.input)
-		o.addNode(g.p.output)
-		o.addGene(g)
+		inherit(g)
 	}
 
 	// Handle trailing genes (if any)
 	for ; j < len(b.oinnov); j++ {
 		g := b.oinnov[j]
-		o.addNode(g.p.input)
-		o.addNode(g.p.output)
-		o.addGene(g)
+		inherit(g)
+	}
+
+	o.sortEvaluation()
+
+	// Nodes inherit their activation and aggregation functions from the
+	// better performing parent when both have them
+	for _, id := range a.outputs {
+		o.acts[id] = a.acts[id]
+		o.aggs[id] = a.aggs[id]
+	}
+
+	for id := range o.acts {
+		if x, ok := a.acts[id]; ok {
+			o.acts[id] = x
+			o.aggs[id] = a.aggs[id]
+		} else if x, ok := b.acts[id]; ok {
+			o.acts[id] = x
+			o.aggs[id] = b.aggs[id]
+		}
 	}
 
 	return o
 }
+
+// inheritNode adds the node with the given ID to the child ´o´ along with the
+// bias gene of the parent that supplies it, the better performing parent ´a´
+// when both do. A new bias is only drawn when neither parent has one.
+func inheritNode(o *organism, id nodeID, a, b *organism) {
+	if !o.hasNode(id) && o.biasGene(id) == nil {
+		if g := a.biasGene(id); g != nil {
+			o.obias = append(o.obias, g.copy())
+		} else if g := b.biasGene(id); g != nil {
+			o.obias = append(o.obias, g.copy())
+		}
+	}
+
+	o.addNode(id)
+}
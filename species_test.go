@@ -7,13 +7,12 @@ import (
 )
 
 func TestRecombinate(t *testing.T) {
-	newGene := func(p nodePair, w float64, f activationFunction, innov geneID) *gene {
+	newGene := func(p nodePair, w float64, innov geneID) *gene {
 		return &gene{
 			innov:    innov,
 			p:        p,
 			weight:   w,
 			disabled: defaultDisabled,
-			activate: f,
 		}
 	}
 
@@ -34,19 +33,19 @@ func TestRecombinate(t *testing.T) {
 				Outputs: 1,
 			},
 			alphaGenes: []*gene{
-				newGene(nodePair{1, 10}, 1, sigmoid, geneID(1)),
-				newGene(nodePair{2, 10}, 1, sigmoid, geneID(2)),
+				newGene(nodePair{1, 10}, 1, geneID(1)),
+				newGene(nodePair{2, 10}, 1, geneID(2)),
 			},
 			alphaFitness: 1.0,
 			betaGenes: []*gene{
-				newGene(nodePair{1, 10}, 1, sigmoid, geneID(1)),
-				newGene(nodePair{2, 10}, 1, sigmoid, geneID(2)),
+				newGene(nodePair{1, 10}, 1, geneID(1)),
+				newGene(nodePair{2, 10}, 1, geneID(2)),
 			},
 			betaFitness: 0.9,
 
 			expect: []*gene{
-				newGene(nodePair{1, 10}, 1, sigmoid, geneID(1)),
-				newGene(nodePair{2, 10}, 1, sigmoid, geneID(2)),
+				newGene(nodePair{1, 10}, 1, geneID(1)),
+				newGene(nodePair{2, 10}, 1, geneID(2)),
 			},
 		},
 		{
@@ -56,25 +55,25 @@ func TestRecombinate(t *testing.T) {
 				Outputs: 1,
 			},
 			alphaGenes: []*gene{
-				newGene(nodePair{1, 10}, 1, sigmoid, geneID(1)),
-				newGene(nodePair{2, 10}, 1, sigmoid, geneID(2)),
-				newGene(nodePair{3, 10}, 1, sigmoid, geneID(3)),
-				newGene(nodePair{4, 10}, 1, sigmoid, geneID(4)),
-				newGene(nodePair{5, 10}, 1, sigmoid, geneID(5)),
+				newGene(nodePair{1, 10}, 1, geneID(1)),
+				newGene(nodePair{2, 10}, 1, geneID(2)),
+				newGene(nodePair{3, 10}, 1, geneID(3)),
+				newGene(nodePair{4, 10}, 1, geneID(4)),
+				newGene(nodePair{5, 10}, 1, geneID(5)),
 			},
 			alphaFitness: 1.0,
 			betaGenes: []*gene{
-				newGene(nodePair{1, 10}, 1, sigmoid, geneID(1)),
-				newGene(nodePair{2, 10}, 1, sigmoid, geneID(2)),
+				newGene(nodePair{1, 10}, 1, geneID(1)),
+				newGene(nodePair{2, 10}, 1, geneID(2)),
 			},
 			betaFitness: 0.9,
 
 			expect: []*gene{
-				newGene(nodePair{1, 10}, 1, sigmoid, geneID(1)),
-				newGene(nodePair{2, 10}, 1, sigmoid, geneID(2)),
-				newGene(nodePair{3, 10}, 1, sigmoid, geneID(3)),
-				newGene(nodePair{4, 10}, 1, sigmoid, geneID(4)),
-				newGene(nodePair{5, 10}, 1, sigmoid, geneID(5)),
+				newGene(nodePair{1, 10}, 1, geneID(1)),
+				newGene(nodePair{2, 10}, 1, geneID(2)),
+				newGene(nodePair{3, 10}, 1, geneID(3)),
+				newGene(nodePair{4, 10}, 1, geneID(4)),
+				newGene(nodePair{5, 10}, 1, geneID(5)),
 			},
 		},
 		{
@@ -84,25 +83,25 @@ func TestRecombinate(t *testing.T) {
 				Outputs: 1,
 			},
 			alphaGenes: []*gene{
-				newGene(nodePair{1, 10}, 1, sigmoid, geneID(1)),
-				newGene(nodePair{2, 10}, 1, sigmoid, geneID(2)),
+				newGene(nodePair{1, 10}, 1, geneID(1)),
+				newGene(nodePair{2, 10}, 1, geneID(2)),
 			},
 			alphaFitness: 1.0,
 			betaGenes: []*gene{
-				newGene(nodePair{1, 10}, 1, sigmoid, geneID(1)),
-				newGene(nodePair{2, 10}, 1, sigmoid, geneID(2)),
-				newGene(nodePair{3, 10}, 1, sigmoid, geneID(3)),
-				newGene(nodePair{4, 10}, 1, sigmoid, geneID(4)),
-				newGene(nodePair{5, 10}, 1, sigmoid, geneID(5)),
+				newGene(nodePair{1, 10}, 1, geneID(1)),
+				newGene(nodePair{2, 10}, 1, geneID(2)),
+				newGene(nodePair{3, 10}, 1, geneID(3)),
+				newGene(nodePair{4, 10}, 1, geneID(4)),
+				newGene(nodePair{5, 10}, 1, geneID(5)),
 			},
 			betaFitness: 0.9,
 
 			expect: []*gene{
-				newGene(nodePair{1, 10}, 1, sigmoid, geneID(1)),
-				newGene(nodePair{2, 10}, 1, sigmoid, geneID(2)),
-				newGene(nodePair{3, 10}, 1, sigmoid, geneID(3)),
-				newGene(nodePair{4, 10}, 1, sigmoid, geneID(4)),
-				newGene(nodePair{5, 10}, 1, sigmoid, geneID(5)),
+				newGene(nodePair{1, 10}, 1, geneID(1)),
+				newGene(nodePair{2, 10}, 1, geneID(2)),
+				newGene(nodePair{3, 10}, 1, geneID(3)),
+				newGene(nodePair{4, 10}, 1, geneID(4)),
+				newGene(nodePair{5, 10}, 1, geneID(5)),
 			},
 		},
 		{
@@ -112,24 +111,24 @@ func TestRecombinate(t *testing.T) {
 				Outputs: 1,
 			},
 			alphaGenes: []*gene{
-				newGene(nodePair{1, 10}, 1, sigmoid, geneID(1)),
-				newGene(nodePair{2, 10}, 1, sigmoid, geneID(2)),
-				newGene(nodePair{4, 10}, 1, sigmoid, geneID(4)),
+				newGene(nodePair{1, 10}, 1, geneID(1)),
+				newGene(nodePair{2, 10}, 1, geneID(2)),
+				newGene(nodePair{4, 10}, 1, geneID(4)),
 			},
 			alphaFitness: 1.0,
 			betaGenes: []*gene{
-				newGene(nodePair{1, 10}, 1, sigmoid, geneID(1)),
-				newGene(nodePair{3, 10}, 1, sigmoid, geneID(3)),
-				newGene(nodePair{5, 10}, 1, sigmoid, geneID(5)),
+				newGene(nodePair{1, 10}, 1, geneID(1)),
+				newGene(nodePair{3, 10}, 1, geneID(3)),
+				newGene(nodePair{5, 10}, 1, geneID(5)),
 			},
 			betaFitness: 0.9,
 
 			expect: []*gene{
-				newGene(nodePair{1, 10}, 1, sigmoid, geneID(1)),
-				newGene(nodePair{2, 10}, 1, sigmoid, geneID(2)),
-				newGene(nodePair{3, 10}, 1, sigmoid, geneID(3)),
-				newGene(nodePair{4, 10}, 1, sigmoid, geneID(4)),
-				newGene(nodePair{5, 10}, 1, sigmoid, geneID(5)),
+				newGene(nodePair{1, 10}, 1, geneID(1)),
+				newGene(nodePair{2, 10}, 1, geneID(2)),
+				newGene(nodePair{3, 10}, 1, geneID(3)),
+				newGene(nodePair{4, 10}, 1, geneID(4)),
+				newGene(nodePair{5, 10}, 1, geneID(5)),
 			},
 		},
 		{
@@ -139,26 +138,26 @@ func TestRecombinate(t *testing.T) {
 				Outputs: 1,
 			},
 			alphaGenes: []*gene{
-				newGene(nodePair{1, 10}, 1, sigmoid, geneID(1)),
-				newGene(nodePair{2, 10}, 1, sigmoid, geneID(2)),
-				newGene(nodePair{3, 10}, 1, sigmoid, geneID(3)),
-				newGene(nodePair{4, 10}, 1, sigmoid, geneID(4)),
-				newGene(nodePair{5, 10}, 1, sigmoid, geneID(5)),
+				newGene(nodePair{1, 10}, 1, geneID(1)),
+				newGene(nodePair{2, 10}, 1, geneID(2)),
+				newGene(nodePair{3, 10}, 1, geneID(3)),
+				newGene(nodePair{4, 10}, 1, geneID(4)),
+				newGene(nodePair{5, 10}, 1, geneID(5)),
 			},
 			alphaFitness: 1.0,
 			betaGenes: []*gene{
-				newGene(nodePair{1, 10}, 1, sigmoid, geneID(1)),
-				newGene(nodePair{3, 10}, 1, sigmoid, geneID(3)),
-				newGene(nodePair{5, 10}, 1, sigmoid, geneID(5)),
+				newGene(nodePair{1, 10}, 1, geneID(1)),
+				newGene(nodePair{3, 10}, 1, geneID(3)),
+				newGene(nodePair{5, 10}, 1, geneID(5)),
 			},
 			betaFitness: 0.9,
 
 			expect: []*gene{
-				newGene(nodePair{1, 10}, 1, sigmoid, geneID(1)),
-				newGene(nodePair{2, 10}, 1, sigmoid, geneID(2)),
-				newGene(nodePair{3, 10}, 1, sigmoid, geneID(3)),
-				newGene(nodePair{4, 10}, 1, sigmoid, geneID(4)),
-				newGene(nodePair{5, 10}, 1, sigmoid, geneID(5)),
+				newGene(nodePair{1, 10}, 1, geneID(1)),
+				newGene(nodePair{2, 10}, 1, geneID(2)),
+				newGene(nodePair{3, 10}, 1, geneID(3)),
+				newGene(nodePair{4, 10}, 1, geneID(4)),
+				newGene(nodePair{5, 10}, 1, geneID(5)),
 			},
 		},
 		{
@@ -168,26 +167,26 @@ func TestRecombinate(t *testing.T) {
 				Outputs: 1,
 			},
 			alphaGenes: []*gene{
-				newGene(nodePair{1, 10}, 1, sigmoid, geneID(1)),
-				newGene(nodePair{3, 10}, 1, sigmoid, geneID(3)),
-				newGene(nodePair{5, 10}, 1, sigmoid, geneID(5)),
+				newGene(nodePair{1, 10}, 1, geneID(1)),
+				newGene(nodePair{3, 10}, 1, geneID(3)),
+				newGene(nodePair{5, 10}, 1, geneID(5)),
 			},
 			alphaFitness: 1.0,
 			betaGenes: []*gene{
-				newGene(nodePair{1, 10}, 1, sigmoid, geneID(1)),
-				newGene(nodePair{2, 10}, 1, sigmoid, geneID(2)),
-				newGene(nodePair{3, 10}, 1, sigmoid, geneID(3)),
-				newGene(nodePair{4, 10}, 1, sigmoid, geneID(4)),
-				newGene(nodePair{5, 10}, 1, sigmoid, geneID(5)),
+				newGene(nodePair{1, 10}, 1, geneID(1)),
+				newGene(nodePair{2, 10}, 1, geneID(2)),
+				newGene(nodePair{3, 10}, 1, geneID(3)),
+				newGene(nodePair{4, 10}, 1, geneID(4)),
+				newGene(nodePair{5, 10}, 1, geneID(5)),
 			},
 			betaFitness: 0.9,
 
 			expect: []*gene{
-				newGene(nodePair{1, 10}, 1, sigmoid, geneID(1)),
-				newGene(nodePair{2, 10}, 1, sigmoid, geneID(2)),
-				newGene(nodePair{3, 10}, 1, sigmoid, geneID(3)),
-				newGene(nodePair{4, 10}, 1, sigmoid, geneID(4)),
-				newGene(nodePair{5, 10}, 1, sigmoid, geneID(5)),
+				newGene(nodePair{1, 10}, 1, geneID(1)),
+				newGene(nodePair{2, 10}, 1, geneID(2)),
+				newGene(nodePair{3, 10}, 1, geneID(3)),
+				newGene(nodePair{4, 10}, 1, geneID(4)),
+				newGene(nodePair{5, 10}, 1, geneID(5)),
 			},
 		},
 		{
@@ -197,28 +196,28 @@ func TestRecombinate(t *testing.T) {
 				Outputs: 1,
 			},
 			alphaGenes: []*gene{
-				newGene(nodePair{1, 10}, 2, sigmoid, geneID(1)),
-				newGene(nodePair{2, 10}, 2, sigmoid, geneID(2)),
-				newGene(nodePair{3, 10}, 2, sigmoid, geneID(3)),
-				newGene(nodePair{4, 10}, 2, sigmoid, geneID(4)),
-				newGene(nodePair{5, 10}, 2, sigmoid, geneID(5)),
+				newGene(nodePair{1, 10}, 2, geneID(1)),
+				newGene(nodePair{2, 10}, 2, geneID(2)),
+				newGene(nodePair{3, 10}, 2, geneID(3)),
+				newGene(nodePair{4, 10}, 2, geneID(4)),
+				newGene(nodePair{5, 10}, 2, geneID(5)),
 			},
 			alphaFitness: 1.0,
 			betaGenes: []*gene{
-				newGene(nodePair{1, 10}, 1, sigmoid, geneID(1)),
-				newGene(nodePair{2, 10}, 1, sigmoid, geneID(2)),
-				newGene(nodePair{3, 10}, 1, sigmoid, geneID(3)),
-				newGene(nodePair{4, 10}, 1, sigmoid, geneID(4)),
-				newGene(nodePair{5, 10}, 1, sigmoid, geneID(5)),
+				newGene(nodePair{1, 10}, 1, geneID(1)),
+				newGene(nodePair{2, 10}, 1, geneID(2)),
+				newGene(nodePair{3, 10}, 1, geneID(3)),
+				newGene(nodePair{4, 10}, 1, geneID(4)),
+				newGene(nodePair{5, 10}, 1, geneID(5)),
 			},
 			betaFitness: 0.9,
 
 			expect: []*gene{
-				newGene(nodePair{1, 10}, 2, sigmoid, geneID(1)),
-				newGene(nodePair{2, 10}, 2, sigmoid, geneID(2)),
-				newGene(nodePair{3, 10}, 2, sigmoid, geneID(3)),
-				newGene(nodePair{4, 10}, 2, sigmoid, geneID(4)),
-				newGene(nodePair{5, 10}, 2, sigmoid, geneID(5)),
+				newGene(nodePair{1, 10}, 2, geneID(1)),
+				newGene(nodePair{2, 10}, 2, geneID(2)),
+				newGene(nodePair{3, 10}, 2, geneID(3)),
+				newGene(nodePair{4, 10}, 2, geneID(4)),
+				newGene(nodePair{5, 10}, 2, geneID(5)),
 			},
 		},
 		{
@@ -228,37 +227,38 @@ func TestRecombinate(t *testing.T) {
 				Outputs: 1,
 			},
 			alphaGenes: []*gene{
-				newGene(nodePair{1, 10}, 1, sigmoid, geneID(1)),
-				newGene(nodePair{2, 10}, 1, sigmoid, geneID(2)),
-				newGene(nodePair{3, 10}, 1, sigmoid, geneID(3)),
-				newGene(nodePair{4, 10}, 1, sigmoid, geneID(4)),
-				newGene(nodePair{5, 10}, 1, sigmoid, geneID(5)),
+				newGene(nodePair{1, 10}, 1, geneID(1)),
+				newGene(nodePair{2, 10}, 1, geneID(2)),
+				newGene(nodePair{3, 10}, 1, geneID(3)),
+				newGene(nodePair{4, 10}, 1, geneID(4)),
+				newGene(nodePair{5, 10}, 1, geneID(5)),
 			},
 			alphaFitness: 0.9,
 			betaGenes: []*gene{
-				newGene(nodePair{1, 10}, 2, sigmoid, geneID(1)),
-				newGene(nodePair{2, 10}, 2, sigmoid, geneID(2)),
-				newGene(nodePair{3, 10}, 2, sigmoid, geneID(3)),
-				newGene(nodePair{4, 10}, 2, sigmoid, geneID(4)),
-				newGene(nodePair{5, 10}, 2, sigmoid, geneID(5)),
+				newGene(nodePair{1, 10}, 2, geneID(1)),
+				newGene(nodePair{2, 10}, 2, geneID(2)),
+				newGene(nodePair{3, 10}, 2, geneID(3)),
+				newGene(nodePair{4, 10}, 2, geneID(4)),
+				newGene(nodePair{5, 10}, 2, geneID(5)),
 			},
 			betaFitness: 1.0,
 
 			expect: []*gene{
-				newGene(nodePair{1, 10}, 2, sigmoid, geneID(1)),
-				newGene(nodePair{2, 10}, 2, sigmoid, geneID(2)),
-				newGene(nodePair{3, 10}, 2, sigmoid, geneID(3)),
-				newGene(nodePair{4, 10}, 2, sigmoid, geneID(4)),
-				newGene(nodePair{5, 10}, 2, sigmoid, geneID(5)),
+				newGene(nodePair{1, 10}, 2, geneID(1)),
+				newGene(nodePair{2, 10}, 2, geneID(2)),
+				newGene(nodePair{3, 10}, 2, geneID(3)),
+				newGene(nodePair{4, 10}, 2, geneID(4)),
+				newGene(nodePair{5, 10}, 2, geneID(5)),
 			},
 		},
 	}
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			s := newCleanSpecies(test.conf)
-			a := newCleanOrganism(test.conf)
-			b := newCleanOrganism(test.conf)
+			reg := newRegistry(test.conf)
+			s := newCleanSpecies(test.conf, reg)
+			a := newCleanOrganism(test.conf, reg)
+			b := newCleanOrganism(test.conf, reg)
 
 			for _, g := range test.alphaGenes {
 				a.nodes[g.p.input] = 0
@@ -284,3 +284,132 @@ func TestRecombinate(t *testing.T) {
 		})
 	}
 }
+
+func TestRecombinateBias(t *testing.T) {
+	conf := &Configuration{
+		Inputs:         1,
+		Outputs:        1,
+		BiasInitStdDev: 1,
+	}
+	reg := newRegistry(conf)
+	s := newCleanSpecies(conf, reg)
+
+	newParent := func(fitness float64, bias map[nodeID]float64, pairs ...nodePair) *organism {
+		o := newCleanOrganism(conf, reg)
+		o.inputs[0], o.outputs[0] = 1, 10
+		o.terminalNodes[1], o.terminalNodes[10] = true, true
+		o.fitness = fitness
+
+		for _, p := range pairs {
+			o.nodes[p.input] = 0
+			o.nodes[p.output] = 0
+			o.addGene(newGene(reg.connect(p), p, 1))
+		}
+
+		for id, w := range bias {
+			p := nodePair{biasID, id}
+			o.obias = append(o.obias, newGene(reg.connect(p), p, w))
+		}
+
+		return o
+	}
+
+	a := newParent(1, map[nodeID]float64{5: 3}, nodePair{1, 5}, nodePair{5, 10})
+	b := newParent(0.5, map[nodeID]float64{5: 4, 6: 7}, nodePair{1, 5}, nodePair{5, 10}, nodePair{1, 6}, nodePair{6, 10})
+
+	// The bias genes are inherited along with the nodes, from the better
+	// performing parent when both have the node
+	c := s.recombinate(a, b)
+	require.Len(t, c.obias, 2)
+	require.Equal(t, 3.0, c.biasGene(5).weight)
+	require.Equal(t, 7.0, c.biasGene(6).weight)
+}
+
+func TestStagnant(t *testing.T) {
+	tests := []struct {
+		name       string
+		dropOffAge int
+		generation int
+		improved   int
+		expect     bool
+	}{
+		{
+			name:       "disabled",
+			dropOffAge: 0,
+			generation: 100,
+			improved:   0,
+			expect:     false,
+		},
+		{
+			name:       "recently improved",
+			dropOffAge: 10,
+			generation: 15,
+			improved:   10,
+			expect:     false,
+		},
+		{
+			name:       "drop off age reached",
+			dropOffAge: 10,
+			generation: 20,
+			improved:   10,
+			expect:     true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			conf := &Configuration{DropOffAge: test.dropOffAge}
+			s := newCleanSpecies(conf, newRegistry(conf))
+			s.generation = test.generation
+			s.improved = test.improved
+
+			require.Equal(t, test.expect, s.stagnant())
+		})
+	}
+}
+
+func TestSurvivors(t *testing.T) {
+	tests := []struct {
+		name       string
+		threshold  float64
+		population int
+		expect     int
+	}{
+		{
+			name:       "unset",
+			threshold:  0,
+			population: 10,
+			expect:     10,
+		},
+		{
+			name:       "all",
+			threshold:  1,
+			population: 10,
+			expect:     10,
+		},
+		{
+			name:       "rounded up",
+			threshold:  0.25,
+			population: 10,
+			expect:     3,
+		},
+		{
+			name:       "at least one",
+			threshold:  0.01,
+			population: 10,
+			expect:     1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			conf := &Configuration{SurvivalThreshold: test.threshold}
+			s := newCleanSpecies(conf, newRegistry(conf))
+			for i := 0; i < test.population; i++ {
+				s.add(&organism{})
+			}
+
+			require.Equal(t, test.expect, s.survivors())
+		})
+	}
+}
@@ -0,0 +1,175 @@
+package neater
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+type (
+	// Summary summarizes a distribution of values
+	Summary struct {
+		Mean   float64
+		Median float64
+		StdDev float64
+		Min    float64
+		Max    float64
+	}
+
+	// SpeciesStats describes a species as it was when its generation was
+	// evaluated
+	SpeciesStats struct {
+		// ID is the species ID
+		ID uint64
+
+		// Age is the number of generations the species has existed
+		Age int
+
+		// Size is the number of organisms in the species
+		Size int
+
+		// Fitness is the raw fitness of the species' best organism
+		Fitness float64
+	}
+
+	// OrganismStats describes an organism as it was when its generation was
+	// evaluated
+	OrganismStats struct {
+		// ID is the organism ID
+		ID uint64
+
+		// Fitness is the organism's raw fitness
+		Fitness float64
+
+		// Nodes is the number of nodes in the organism's genome, excluding
+		// the bias node
+		Nodes int
+
+		// Genes is the number of enabled genes in the organism's genome
+		Genes int
+	}
+
+	// Stats is a snapshot of the population taken when a generation has been
+	// trained
+	Stats struct {
+		// Iterations is the number of generations trained since the start
+		Iterations int
+
+		// NbrSpecies is the number of species at the end of the iteration
+		NbrSpecies int
+
+		// Evaluations is the number of fitness evaluations made since the
+		// start
+		Evaluations int
+
+		// Elapsed is the time spent training since the start
+		Elapsed time.Duration
+
+		// BestFitness is the best fitness found since the start
+		BestFitness float64
+		// Improved is the iteration in which BestFitness was last improved
+		Improved int
+
+		// Extinct is the number of species that were removed in the last
+		// iteration, either because they were stagnant or because they
+		// weren't allotted any offspring
+		Extinct int
+		// TotalExtinct is the number of species removed since the start
+		TotalExtinct int
+
+		// Fitness summarizes the raw fitness of the evaluated population
+		Fitness Summary
+
+		// Nodes summarizes the number of nodes per genome
+		Nodes Summary
+
+		// Genes summarizes the number of enabled genes per genome
+		Genes Summary
+
+		// Complexity summarizes the number of hidden nodes and genes,
+		// disabled genes included, per genome
+		Complexity Summary
+
+		// Simplifying tells whether the population was in a simplifying
+		// phase when the generation was mutated, see PhasedPruning
+		Simplifying bool
+
+		// Species describes every evaluated species, best species first
+		Species []SpeciesStats
+
+		// Best describes the best organism of the generation
+		Best OrganismStats
+	}
+)
+
+// summarize summarizes ´values´, the values are sorted in place
+func summarize(values []float64) Summary {
+	if len(values) == 0 {
+		return Summary{}
+	}
+
+	sort.Float64s(values)
+
+	var s Summary
+	s.Min = values[0]
+	s.Max = values[len(values)-1]
+
+	if l := len(values); l%2 == 0 {
+		s.Median = (values[l/2-1] + values[l/2]) / 2
+	} else {
+		s.Median = values[l/2]
+	}
+
+	for _, v := range values {
+		s.Mean += v
+	}
+	s.Mean /= float64(len(values))
+
+	for _, v := range values {
+		s.StdDev += (v - s.Mean) * (v - s.Mean)
+	}
+	s.StdDev = math.Sqrt(s.StdDev / float64(len(values)))
+
+	return s
+}
+
+// sample records the distribution of fitness and genome sizes of the
+// evaluated species, which must be sorted best species first
+func (s *Stats) sample(species []*species) {
+	var (
+		fitness    []float64
+		nodes      []float64
+		genes      []float64
+		complexity []float64
+	)
+
+	s.Species = make([]SpeciesStats, 0, len(species))
+	for _, x := range species {
+		s.Species = append(s.Species, SpeciesStats{
+			ID:      uint64(x.id),
+			Age:     x.generation,
+			Size:    len(x.population),
+			Fitness: x.champ.fitness,
+		})
+
+		for _, o := range x.population {
+			fitness = append(fitness, o.fitness)
+			nodes = append(nodes, float64(len(o.nodes)))
+			genes = append(genes, float64(o.enabledGenes()))
+			complexity = append(complexity, float64(o.complexity()))
+		}
+	}
+
+	s.Fitness = summarize(fitness)
+	s.Nodes = summarize(nodes)
+	s.Genes = summarize(genes)
+	s.Complexity = summarize(complexity)
+
+	champ := species[0].champ
+	s.Best = OrganismStats{
+		ID:      uint64(champ.id),
+		Fitness: champ.fitness,
+		Nodes:   len(champ.nodes),
+		Genes:   champ.enabledGenes(),
+	}
+}
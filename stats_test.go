@@ -0,0 +1,83 @@
+package neater
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummarize(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		expect Summary
+	}{
+		{
+			name:   "empty",
+			values: nil,
+			expect: Summary{},
+		},
+		{
+			name:   "single",
+			values: []float64{3},
+			expect: Summary{Mean: 3, Median: 3, StdDev: 0, Min: 3, Max: 3},
+		},
+		{
+			name:   "odd",
+			values: []float64{5, 1, 3},
+			expect: Summary{Mean: 3, Median: 3, StdDev: math.Sqrt(8.0 / 3), Min: 1, Max: 5},
+		},
+		{
+			name:   "even",
+			values: []float64{4, 1, 2, 5},
+			expect: Summary{Mean: 3, Median: 3, StdDev: math.Sqrt(2.5), Min: 1, Max: 5},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := summarize(test.values)
+			require.Equal(t, test.expect.Mean, s.Mean)
+			require.Equal(t, test.expect.Median, s.Median)
+			require.InDelta(t, test.expect.StdDev, s.StdDev, 1e-12)
+			require.Equal(t, test.expect.Min, s.Min)
+			require.Equal(t, test.expect.Max, s.Max)
+		})
+	}
+}
+
+func TestHistory(t *testing.T) {
+	tf, cf := xorFactories()
+
+	conf := xorConfiguration(1)
+	n, err := NewNeat(conf)
+	require.NoError(t, err)
+
+	for i := 0; i < 5; i++ {
+		n.Train(tf, cf)
+	}
+
+	h := n.History()
+	require.Len(t, h, 5)
+	require.Equal(t, n.Stats().Iterations, h[4].Iterations)
+
+	for i, s := range h {
+		require.Equal(t, i+1, s.Iterations)
+		require.True(t, s.Fitness.Min <= s.Fitness.Median)
+		require.True(t, s.Fitness.Median <= s.Fitness.Max)
+		require.Equal(t, s.Fitness.Max, s.Best.Fitness)
+		require.NotEmpty(t, s.Species)
+
+		size := 0
+		for _, x := range s.Species {
+			size += x.Size
+		}
+
+		if i == 0 {
+			require.Equal(t, conf.InitialPopulationSize, size)
+		} else {
+			require.Equal(t, conf.PopulationSize, size)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package neater
+
+import (
+	"sort"
+)
+
+// successors returns the nodes each node connects to, disabled genes
+// included so that re-enabling a gene can't break the evaluation order
+func (o *organism) successors() map[nodeID][]nodeID {
+	succ := make(map[nodeID][]nodeID, len(o.nodes))
+	for _, g := range o.oinnov {
+		succ[g.p.input] = append(succ[g.p.input], g.p.output)
+	}
+
+	return succ
+}
+
+// nodeOrder returns every node in topological order, a node comes after all
+// the nodes connecting to it. The connections closing a cycle, which only
+// recurrent genomes have, are the only ones pointing backwards.
+func (o *organism) nodeOrder() []nodeID {
+	succ := o.successors()
+
+	ids := make([]nodeID, 0, len(o.nodes))
+	for id := range o.nodes {
+		ids = append(ids, id)
+	}
+
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i] < ids[j]
+	})
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	// The reverse of the depth-first post-order is a topological order,
+	// edges to nodes being visited close a cycle and are skipped
+	state := make(map[nodeID]int, len(ids))
+	post := make([]nodeID, 0, len(ids))
+
+	var visit func(id nodeID)
+	visit = func(id nodeID) {
+		state[id] = visiting
+		for _, x := range succ[id] {
+			if state[x] == unvisited {
+				visit(x)
+			}
+		}
+		state[id] = visited
+		post = append(post, id)
+	}
+
+	for _, id := range ids {
+		if state[id] == unvisited {
+			visit(id)
+		}
+	}
+
+	for i, j := 0, len(post)-1; i < j; i, j = i+1, j-1 {
+		post[i], post[j] = post[j], post[i]
+	}
+
+	return post
+}
+
+// sortEvaluation orders the genes of the evaluation order by the position of
+// their output node in the node order, genes sharing an output node are kept
+// in innovation order. Every node then receives all its inputs before it's
+// read.
+func (o *organism) sortEvaluation() {
+	rank := make(map[nodeID]int, len(o.nodes))
+	for i, id := range o.nodeOrder() {
+		rank[id] = i
+	}
+
+	o.oeval = append(o.oeval[:0], o.oinnov...)
+	sort.SliceStable(o.oeval, func(i, j int) bool {
+		return rank[o.oeval[i].p.output] < rank[o.oeval[j].p.output]
+	})
+}
+
+// reaches reports whether there's a path from node ´from´ to node ´to´ in
+// the graph ´succ´ returned by successors
+func reaches(succ map[nodeID][]nodeID, from, to nodeID) bool {
+	seen := map[nodeID]bool{from: true}
+	stack := []nodeID{from}
+
+	for len(stack) > 0 {
+		id := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		if id == to {
+			return true
+		}
+
+		for _, x := range succ[id] {
+			if !seen[x] {
+				seen[x] = true
+				stack = append(stack, x)
+			}
+		}
+	}
+
+	return false
+}
+
+// closesCycle reports whether connecting the nodes of ´p´ would create a
+// cycle, that is whether the connection would be recurrent
+func (o *organism) closesCycle(p nodePair) bool {
+	return cyclic(o.successors(), p)
+}
+
+// cyclic reports whether connecting the nodes of ´p´ would create a cycle in
+// the graph ´succ´ returned by successors
+func cyclic(succ map[nodeID][]nodeID, p nodePair) bool {
+	return p.input == p.output || reaches(succ, p.output, p.input)
+}
+
+// connected reports whether the organism has a gene connecting the nodes of
+// ´p´
+func (o *organism) connected(p nodePair) bool {
+	for _, g := range o.oinnov {
+		if g.p == p {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,139 @@
+package neater
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// requireAcyclic checks that every gene of ´o´ points forward in the node
+// order and that the evaluation order follows it
+func requireAcyclic(t *testing.T, o *organism) {
+	rank := make(map[nodeID]int)
+	for i, id := range o.nodeOrder() {
+		rank[id] = i
+	}
+
+	for _, g := range o.oinnov {
+		require.Less(t, rank[g.p.input], rank[g.p.output], "gene %s", g.p)
+	}
+
+	require.Len(t, o.oeval, len(o.oinnov))
+	for i := 1; i < len(o.oeval); i++ {
+		require.LessOrEqual(t, rank[o.oeval[i-1].p.output], rank[o.oeval[i].p.output])
+	}
+}
+
+func TestNodeOrder(t *testing.T) {
+	conf := &Configuration{
+		Inputs:      2,
+		Outputs:     1,
+		activation:  activation{ActivateUnit, unit},
+		aggregation: aggregation{AggregateSum, sum},
+	}
+
+	reg := newRegistry(conf)
+	inputs, outputs := createInputsOuputs(reg, conf)
+	o := newOrganism(conf, reg, inputs, outputs, withConnectStrategy(connectNone))
+
+	// Genes are added in an order unrelated to the topology
+	for _, p := range []nodePair{{7, 3}, {5, 7}, {1, 5}, {6, 3}, {2, 6}, {5, 6}} {
+		o.addNode(p.input)
+		o.addNode(p.output)
+		o.addGene(newGene(reg.nextInnov(), p, defaultWeight))
+	}
+
+	requireAcyclic(t, o)
+
+	require.True(t, o.closesCycle(nodePair{3, 1}))
+	require.True(t, o.closesCycle(nodePair{6, 5}))
+	require.True(t, o.closesCycle(nodePair{7, 7}))
+	require.False(t, o.closesCycle(nodePair{1, 6}))
+	require.False(t, o.closesCycle(nodePair{7, 6}))
+
+	genes := len(o.oinnov)
+	require.False(t, o.addGene(newGene(reg.nextInnov(), nodePair{6, 5}, defaultWeight)))
+	require.Len(t, o.oinnov, genes)
+	requireAcyclic(t, o)
+}
+
+func TestMutateConnectNodes(t *testing.T) {
+	tests := []struct {
+		name      string
+		recurrent bool
+	}{
+		{name: "feed-forward", recurrent: false},
+		{name: "recurrent", recurrent: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			conf := xorConfiguration(1)
+			conf.Inputs = 3
+			conf.Outputs = 2
+			conf.Recurrent = test.recurrent
+			if test.recurrent {
+				conf.RecurrentConnProb = 1
+			}
+			require.NoError(t, conf.resolve())
+
+			reg := newRegistry(conf)
+			inputs, outputs := createInputsOuputs(reg, conf)
+			o := newOrganism(conf, reg, inputs, outputs)
+
+			for i := 0; i < 10; i++ {
+				o.mutateAddNode()
+			}
+
+			// Keep connecting nodes long after the organism is saturated
+			genes := len(o.oinnov)
+			for i := 0; i < 200; i++ {
+				n := len(o.oinnov)
+				o.mutateConnectNodes()
+
+				if len(o.oinnov) > n {
+					g := o.oinnov[n]
+					require.Equal(t, test.recurrent, o.closesCycle(g.p))
+				}
+			}
+
+			require.Greater(t, len(o.oinnov), genes)
+			if !test.recurrent {
+				requireAcyclic(t, o)
+			}
+		})
+	}
+}
+
+func TestRecombinateCycle(t *testing.T) {
+	conf := &Configuration{
+		Inputs:      2,
+		Outputs:     1,
+		activation:  activation{ActivateUnit, unit},
+		aggregation: aggregation{AggregateSum, sum},
+	}
+
+	reg := newRegistry(conf)
+	inputs, outputs := createInputsOuputs(reg, conf)
+
+	// Both parents split both connections but connect the new nodes in
+	// opposite directions
+	a := newOrganism(conf, reg, inputs, outputs)
+	reg.rand = &stubRandom{ints: []int{0, 1}}
+	a.mutateAddNode()
+	a.mutateAddNode()
+	b := a.copy()
+
+	x, y := a.oinnov[2].p.output, a.oinnov[4].p.output
+	a.addGene(newGene(reg.connect(nodePair{x, y}), nodePair{x, y}, defaultWeight))
+	b.addGene(newGene(reg.connect(nodePair{y, x}), nodePair{y, x}, defaultWeight))
+	a.fitness = 1
+
+	s := newCleanSpecies(conf, reg)
+	o := s.recombinate(a, b)
+
+	// The gene of the fitter parent is kept, the other one is left out
+	require.True(t, o.connected(nodePair{x, y}))
+	require.False(t, o.connected(nodePair{y, x}))
+	requireAcyclic(t, o)
+}
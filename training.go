@@ -20,6 +20,16 @@ type (
 		Reset()
 	}
 
+	// BatchTrainer is a Trainer whose whole set of inputs is known up
+	// front. Organisms are evaluated on a BatchTrainer's inputs in a single
+	// call, Next isn't used.
+	BatchTrainer interface {
+		Trainer
+
+		// Batch returns every input of the set
+		Batch() [][]float64
+	}
+
 	TrainerFactory struct {
 		// Inputs is the number of inputs
 		Inputs func() int
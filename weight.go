@@ -0,0 +1,101 @@
+package neater
+
+import (
+	"math"
+)
+
+type weightInit int
+
+const (
+	// WeightInitConstant gives new connections a weight of 1 and bias
+	// connections a weight of InitialBiasWeight
+	WeightInitConstant = "constant"
+	// WeightInitUniform draws weights uniformly from [WeightInitMin,
+	// WeightInitMax), or [BiasInitMin, BiasInitMax) for bias connections
+	WeightInitUniform = "uniform"
+	// WeightInitNormal draws weights from a normal distribution of mean
+	// WeightInitMean and standard deviation WeightInitStdDev, or
+	// InitialBiasWeight and BiasInitStdDev for bias connections
+	WeightInitNormal = "normal"
+	// WeightInitXavier draws weights from a normal distribution of mean 0
+	// and variance 1/n, n being the number of connections entering the node
+	WeightInitXavier = "xavier"
+	// WeightInitHe draws weights from a normal distribution of mean 0 and
+	// variance 2/n, n being the number of connections entering the node
+	WeightInitHe = "he"
+)
+
+const (
+	weightInitConstant = weightInit(iota)
+	weightInitUniform
+	weightInitNormal
+	weightInitXavier
+	weightInitHe
+)
+
+// weightInits maps the weight initialization names to weight initializations
+var weightInits = map[string]weightInit{
+	WeightInitConstant: weightInitConstant,
+	WeightInitUniform:  weightInitUniform,
+	WeightInitNormal:   weightInitNormal,
+	WeightInitXavier:   weightInitXavier,
+	WeightInitHe:       weightInitHe,
+	"":                 weightInitConstant,
+}
+
+// newWeight draws the weight of a new connection entering a node with
+// ´fanIn´ incoming connections, the new one included
+func (o *organism) newWeight(fanIn int) float64 {
+	n := float64(max(1, fanIn))
+
+	switch o.conf.weightInit {
+	case weightInitUniform:
+		return o.conf.WeightInitMin + o.reg.rand.Float64()*(o.conf.WeightInitMax-o.conf.WeightInitMin)
+	case weightInitNormal:
+		return o.conf.WeightInitMean + o.reg.rand.NormFloat64()*o.conf.WeightInitStdDev
+	case weightInitXavier:
+		return o.reg.rand.NormFloat64() * math.Sqrt(1/n)
+	case weightInitHe:
+		return o.reg.rand.NormFloat64() * math.Sqrt(2/n)
+	}
+
+	return defaultWeight
+}
+
+// newBias draws the weight of a new bias connection
+func (o *organism) newBias() float64 {
+	switch o.conf.biasInit {
+	case weightInitUniform:
+		return o.conf.BiasInitMin + o.reg.rand.Float64()*(o.conf.BiasInitMax-o.conf.BiasInitMin)
+	case weightInitNormal:
+		if o.conf.BiasInitStdDev == 0 {
+			return o.conf.InitialBiasWeight
+		}
+
+		return o.conf.InitialBiasWeight + o.reg.rand.NormFloat64()*o.conf.BiasInitStdDev
+	case weightInitXavier:
+		return o.reg.rand.NormFloat64()
+	case weightInitHe:
+		return o.reg.rand.NormFloat64() * math.Sqrt2
+	}
+
+	return o.conf.InitialBiasWeight
+}
+
+// initWeights draws the weight of every gene of a newly created organism. It's
+// called once all the connections are made so that the fan-in of every node
+// is known.
+func (o *organism) initWeights() {
+	if o.conf.weightInit == weightInitConstant {
+		return
+	}
+
+	fanIn := make(map[nodeID]int, len(o.acts))
+	for _, g := range o.oinnov {
+		fanIn[g.p.output]++
+	}
+
+	for _, g := range o.oinnov {
+		g.weight = o.newWeight(fanIn[g.p.output])
+	}
+}
@@ -0,0 +1,165 @@
+package neater
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewWeight(t *testing.T) {
+	tests := []struct {
+		name   string
+		conf   *Configuration
+		fanIn  int
+		rand   *stubRandom
+		expect float64
+	}{
+		{
+			name:   "constant",
+			conf:   &Configuration{weightInit: weightInitConstant},
+			expect: defaultWeight,
+		},
+		{
+			name:   "uniform",
+			conf:   &Configuration{weightInit: weightInitUniform, WeightInitMin: -2, WeightInitMax: 2},
+			rand:   &stubRandom{floats: []float64{0.25}},
+			expect: -1,
+		},
+		{
+			name:   "normal",
+			conf:   &Configuration{weightInit: weightInitNormal, WeightInitMean: 0.5, WeightInitStdDev: 2},
+			rand:   &stubRandom{normals: []float64{1}},
+			expect: 2.5,
+		},
+		{
+			name:   "xavier",
+			conf:   &Configuration{weightInit: weightInitXavier},
+			fanIn:  4,
+			rand:   &stubRandom{normals: []float64{1}},
+			expect: 0.5,
+		},
+		{
+			name:   "he",
+			conf:   &Configuration{weightInit: weightInitHe},
+			fanIn:  8,
+			rand:   &stubRandom{normals: []float64{1}},
+			expect: 0.5,
+		},
+		{
+			name:   "he without inputs",
+			conf:   &Configuration{weightInit: weightInitHe},
+			fanIn:  0,
+			rand:   &stubRandom{normals: []float64{1}},
+			expect: 1.4142135623730951,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reg := newRegistry(test.conf)
+			if test.rand != nil {
+				reg.rand = test.rand
+			}
+
+			o := &organism{conf: test.conf, reg: reg}
+			require.Equal(t, test.expect, o.newWeight(test.fanIn))
+		})
+	}
+}
+
+func TestNewBias(t *testing.T) {
+	tests := []struct {
+		name   string
+		conf   *Configuration
+		rand   *stubRandom
+		expect float64
+	}{
+		{
+			name:   "constant",
+			conf:   &Configuration{biasInit: weightInitConstant, InitialBiasWeight: 0.5},
+			expect: 0.5,
+		},
+		{
+			name:   "normal without deviation",
+			conf:   &Configuration{biasInit: weightInitNormal, InitialBiasWeight: 0.5},
+			expect: 0.5,
+		},
+		{
+			name:   "normal",
+			conf:   &Configuration{biasInit: weightInitNormal, InitialBiasWeight: 0.5, BiasInitStdDev: 2},
+			rand:   &stubRandom{normals: []float64{1}},
+			expect: 2.5,
+		},
+		{
+			name:   "uniform",
+			conf:   &Configuration{biasInit: weightInitUniform, BiasInitMin: -2, BiasInitMax: 2},
+			rand:   &stubRandom{floats: []float64{0.75}},
+			expect: 1,
+		},
+		{
+			name:   "xavier",
+			conf:   &Configuration{biasInit: weightInitXavier},
+			rand:   &stubRandom{normals: []float64{0.5}},
+			expect: 0.5,
+		},
+		{
+			name:   "he",
+			conf:   &Configuration{biasInit: weightInitHe},
+			rand:   &stubRandom{normals: []float64{1}},
+			expect: 1.4142135623730951,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reg := newRegistry(test.conf)
+			if test.rand != nil {
+				reg.rand = test.rand
+			}
+
+			o := &organism{conf: test.conf, reg: reg}
+			require.Equal(t, test.expect, o.newBias())
+		})
+	}
+}
+
+func TestInitialWeights(t *testing.T) {
+	conf := xorConfiguration(1)
+	conf.InitialHiddenNodes = 2
+	conf.WeightInit = WeightInitUniform
+	conf.WeightInitMin = -3
+	conf.WeightInitMax = -2
+	conf.InitialBiasWeight = 5
+	conf.BiasInitStdDev = 0.1
+
+	n, err := NewNeat(conf)
+	require.NoError(t, err)
+
+	// Every initial organism is drawn independently, bias genes are drawn
+	// around InitialBiasWeight
+	population := n.species[0].population
+	require.Len(t, population, conf.InitialPopulationSize)
+
+	seen := make(map[float64]bool)
+	for _, o := range population {
+		for _, g := range o.oinnov {
+			require.True(t, g.weight >= -3 && g.weight < -2, "weight %v", g.weight)
+			require.False(t, seen[g.weight])
+			seen[g.weight] = true
+		}
+
+		for _, g := range o.obias {
+			require.InDelta(t, 5, g.weight, 1, "bias weight %v", g.weight)
+			require.False(t, seen[g.weight])
+			seen[g.weight] = true
+		}
+	}
+
+	// Connections added by mutation follow the same distribution
+	o := population[0]
+	genes := len(o.oinnov)
+	for len(o.oinnov) == genes {
+		o.mutateConnectNodes()
+	}
+	require.Less(t, o.oinnov[genes].weight, -2.0)
+}